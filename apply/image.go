@@ -0,0 +1,184 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/engine"
+	"github.com/coreos/ignition/internal/distro"
+)
+
+var (
+	ErrImageRaid = errors.New("raid arrays can't be built into images")
+)
+
+// image is a disk image file standing in for the disk at device.
+type image struct {
+	device string
+	file   string
+	loop   string
+}
+
+// parseImage parses an --image argument of the form device=file.
+func parseImage(arg string) (image, error) {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "/") || parts[1] == "" {
+		return image{}, fmt.Errorf("invalid image %q: expected device=file (e.g. /dev/vda=disk.img)", arg)
+	}
+	return image{device: parts[0], file: parts[1]}, nil
+}
+
+// attach attaches the image file to a loop device, scanning it for
+// partitions.
+func (i *image) attach() error {
+	out, err := exec.Command(distro.LosetupCmd(), "--find", "--show", "--partscan", i.file).Output()
+	if err != nil {
+		return fmt.Errorf("failed to attach %q to a loop device: %v", i.file, err)
+	}
+	i.loop = strings.TrimSpace(string(out))
+	return nil
+}
+
+// detach detaches the image file from its loop device.
+func (i image) detach() error {
+	if err := exec.Command(distro.LosetupCmd(), "--detach", i.loop).Run(); err != nil {
+		return fmt.Errorf("failed to detach %q: %v", i.loop, err)
+	}
+	return nil
+}
+
+// mapDevice returns the path on the image's loop device corresponding to dev,
+// which is either the image's device or one of its partitions (e.g. /dev/vda1
+// or /dev/nvme0n1p1), and whether dev is on the image at all. As in the
+// kernel's naming, partition numbers are separated by a "p" exactly when the
+// device's name ends in a digit.
+func (i image) mapDevice(dev string) (string, bool) {
+	if dev == i.device {
+		return i.loop, true
+	}
+	if !strings.HasPrefix(dev, i.device) {
+		return "", false
+	}
+	number := strings.TrimPrefix(dev, i.device)
+	if last := i.device[len(i.device)-1]; last >= '0' && last <= '9' {
+		if !strings.HasPrefix(number, "p") {
+			return "", false
+		}
+		number = number[1:]
+	}
+	if number == "" || strings.Trim(number, "0123456789") != "" {
+		return "", false
+	}
+	return fmt.Sprintf("%sp%s", i.loop, number), true
+}
+
+// imageConfig returns cfg with the devices of its disks and filesystems
+// mapped onto the images' loop devices. Devices which aren't on any image,
+// including udev symlinks such as /dev/disk/by-label/ROOT, are an error, since
+// the stages would otherwise modify the build host's own block devices.
+func imageConfig(cfg types.Config, images []image) (types.Config, error) {
+	if len(cfg.Storage.Raid) > 0 {
+		return types.Config{}, ErrImageRaid
+	}
+	mapDevice := func(dev, path string) (string, error) {
+		for _, i := range images {
+			if mapped, ok := i.mapDevice(dev); ok {
+				return mapped, nil
+			}
+		}
+		return "", fmt.Errorf("%s: device %q isn't on any image", path, dev)
+	}
+
+	disks := []types.Disk{}
+	for idx, d := range cfg.Storage.Disks {
+		dev, err := mapDevice(d.Device, fmt.Sprintf("storage.disks.%d.device", idx))
+		if err != nil {
+			return types.Config{}, err
+		}
+		d.Device = dev
+		disks = append(disks, d)
+	}
+	cfg.Storage.Disks = disks
+
+	filesystems := []types.Filesystem{}
+	for idx, fs := range cfg.Storage.Filesystems {
+		if fs.Mount != nil {
+			m := *fs.Mount
+			dev, err := mapDevice(m.Device, fmt.Sprintf("storage.filesystems.%d.mount.device", idx))
+			if err != nil {
+				return types.Config{}, err
+			}
+			m.Device = dev
+			fs.Mount = &m
+		}
+		filesystems = append(filesystems, fs)
+	}
+	cfg.Storage.Filesystems = filesystems
+
+	return cfg, nil
+}
+
+// buildImages attaches the images given as device=file arguments to loop
+// devices, runs the disks stage against them and, if withFiles is set, runs
+// the mount, files, and umount stages with e.Root as the root.
+func buildImages(e engine.Engine, cfg types.Config, args []string, withFiles bool) (err error) {
+	images := []image{}
+	for _, arg := range args {
+		i, err := parseImage(arg)
+		if err != nil {
+			return err
+		}
+		images = append(images, i)
+	}
+
+	for idx := range images {
+		if err := images[idx].attach(); err != nil {
+			return err
+		}
+		defer func(i image) {
+			if derr := i.detach(); derr != nil && err == nil {
+				err = derr
+			}
+		}(images[idx])
+	}
+
+	cfg, err = imageConfig(cfg, images)
+	if err != nil {
+		return err
+	}
+
+	e.DevicesReady = true
+	if err := e.Run("disks", cfg); err != nil {
+		return err
+	}
+	if !withFiles {
+		return nil
+	}
+
+	if err := e.Run("mount", cfg); err != nil {
+		e.Run("umount", cfg)
+		return err
+	}
+	if err := e.Run("files", cfg); err != nil {
+		e.Run("umount", cfg)
+		return err
+	}
+	return e.Run("umount", cfg)
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestParseImage(t *testing.T) {
+	i, err := parseImage("/dev/vda=out/disk.img")
+	if err != nil || i.device != "/dev/vda" || i.file != "out/disk.img" {
+		t.Errorf("bad image: %+v, %v", i, err)
+	}
+	for _, arg := range []string{"disk.img", "vda=disk.img", "/dev/vda="} {
+		if _, err := parseImage(arg); err == nil {
+			t.Errorf("expected an error parsing %q", arg)
+		}
+	}
+}
+
+func TestMapDevice(t *testing.T) {
+	vda := image{device: "/dev/vda", loop: "/dev/loop3"}
+	nvme := image{device: "/dev/nvme0n1", loop: "/dev/loop4"}
+
+	tests := []struct {
+		image image
+		dev   string
+		out   string
+		ok    bool
+	}{
+		{vda, "/dev/vda", "/dev/loop3", true},
+		{vda, "/dev/vda9", "/dev/loop3p9", true},
+		{vda, "/dev/vdap1", "", false},
+		{vda, "/dev/vdaa1", "", false},
+		{vda, "/dev/vdb1", "", false},
+		{nvme, "/dev/nvme0n1", "/dev/loop4", true},
+		{nvme, "/dev/nvme0n1p12", "/dev/loop4p12", true},
+		{nvme, "/dev/nvme0n12", "", false},
+		{nvme, "/dev/nvme0n1p", "", false},
+	}
+
+	for i, test := range tests {
+		out, ok := test.image.mapDevice(test.dev)
+		if ok != test.ok || out != test.out {
+			t.Errorf("#%d: bad mapping of %q: want %q, %t, got %q, %t", i, test.dev, test.out, test.ok, out, ok)
+		}
+	}
+}
+
+func TestImageConfig(t *testing.T) {
+	images := []image{
+		{device: "/dev/vda", loop: "/dev/loop3"},
+		{device: "/dev/nvme0n1", loop: "/dev/loop4"},
+	}
+	mount := func(dev string) *types.Mount {
+		return &types.Mount{Device: dev, Format: "ext4"}
+	}
+
+	in := types.Config{
+		Storage: types.Storage{
+			Disks: []types.Disk{
+				{Device: "/dev/vda"},
+				{Device: "/dev/nvme0n1"},
+			},
+			Filesystems: []types.Filesystem{
+				{Name: "root", Mount: mount("/dev/vda9")},
+				{Name: "data", Mount: mount("/dev/nvme0n1p12")},
+				{Name: "path", Path: func(p string) *string { return &p }("/sysroot")},
+			},
+		},
+	}
+	out := types.Config{
+		Storage: types.Storage{
+			Disks: []types.Disk{
+				{Device: "/dev/loop3"},
+				{Device: "/dev/loop4"},
+			},
+			Filesystems: []types.Filesystem{
+				{Name: "root", Mount: mount("/dev/loop3p9")},
+				{Name: "data", Mount: mount("/dev/loop4p12")},
+				{Name: "path", Path: func(p string) *string { return &p }("/sysroot")},
+			},
+		},
+	}
+
+	cfg, err := imageConfig(in, images)
+	if err != nil {
+		t.Fatalf("imageConfig: %v", err)
+	}
+	if !reflect.DeepEqual(out, cfg) {
+		t.Errorf("bad config: want %+v, got %+v", out, cfg)
+	}
+	if in.Storage.Filesystems[0].Mount.Device != "/dev/vda9" {
+		t.Errorf("imageConfig modified its input")
+	}
+
+	// devices which aren't on an image would be the build host's own
+	unmapped := []types.Config{
+		{Storage: types.Storage{Disks: []types.Disk{{Device: "/dev/sdb"}}}},
+		{Storage: types.Storage{Filesystems: []types.Filesystem{{Name: "other", Mount: mount("/dev/vdaa1")}}}},
+		{Storage: types.Storage{Filesystems: []types.Filesystem{{Name: "label", Mount: mount("/dev/disk/by-label/ROOT")}}}},
+	}
+	for i, cfg := range unmapped {
+		if _, err := imageConfig(cfg, images); err == nil {
+			t.Errorf("#%d: expected an error for a device which isn't on an image", i)
+		}
+	}
+
+	in.Storage.Raid = []types.Raid{{Name: "md0"}}
+	if _, err := imageConfig(in, images); err != ErrImageRaid {
+		t.Errorf("expected ErrImageRaid, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/engine"
+	"github.com/coreos/ignition/internal/exec"
+	"github.com/coreos/ignition/internal/version"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagVersion      bool
+	flagFetchWorkers int
+	flagMergeConfigs bool
+	flagStrict       bool
+	flagImages       []string
+	rootCmd          = &cobra.Command{
+		Use:   "ignition-apply [--image device=file.img]... config.ign [root]",
+		Short: "ignition-apply will apply the files, directories, links, units, users and groups of an Ignition config to a directory, or its storage section to disk images",
+		Run:   runIgnApply,
+	}
+)
+
+func main() {
+	rootCmd.Flags().BoolVar(&flagVersion, "version", false, "print the version of ignition-apply")
+	rootCmd.Flags().StringArrayVar(&flagImages, "image", nil, "build the disk with the given device path (e.g. /dev/vda) into an image file, then run the files stage into its filesystems if root is given")
+	rootCmd.Flags().IntVar(&flagFetchWorkers, "fetch-workers", exec.DefaultFetchWorkers, "maximum number of remote files to fetch at once")
+	rootCmd.Flags().BoolVar(&flagMergeConfigs, "merge-configs", false, "merge entries with the same path or name when appending referenced configs instead of appending them")
+	rootCmd.Flags().BoolVar(&flagStrict, "strict", false, "reject configs, including referenced ones, containing keys which aren't part of the spec")
+	rootCmd.Execute()
+}
+
+func stdout(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stdout, strings.TrimSpace(format)+"\n", a...)
+}
+
+func stderr(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, strings.TrimSpace(format)+"\n", a...)
+}
+
+func die(format string, a ...interface{}) {
+	stderr(format, a...)
+	os.Exit(1)
+}
+
+func runIgnApply(cmd *cobra.Command, args []string) {
+	if flagVersion {
+		stdout(version.String)
+		return
+	}
+	if !validArgs(args, flagImages) {
+		cmd.Usage()
+		os.Exit(1)
+	}
+	var blob []byte
+	var err error
+	if args[0] == "-" {
+		blob, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		blob, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		die("couldn't read config: %v", err)
+	}
+	parse := config.Parse
+	if flagStrict {
+		parse = config.ParseStrict
+	}
+	cfg, rpt, err := parse(blob)
+	if len(rpt.Entries) > 0 {
+		stdout(rpt.String())
+	}
+	if rpt.IsFatal() {
+		os.Exit(1)
+	}
+	if err != nil {
+		die("couldn't parse config: %v", err)
+	}
+
+	root := "/"
+	if len(args) == 2 {
+		root = args[1]
+		if err := checkRoot(root); err != nil {
+			die("%v", err)
+		}
+	}
+
+	logger := engine.NewLogger(true)
+	defer logger.Close()
+	e := engine.Engine{
+		Root:          root,
+		Logger:        logger,
+		Fetcher:       engine.NewFetcher(logger),
+		FetchWorkers:  flagFetchWorkers,
+		MergeConfigs:  flagMergeConfigs,
+		StrictConfigs: flagStrict,
+	}
+
+	cfg, err = e.Render(cfg)
+	if err != nil {
+		die("couldn't render config: %v", err)
+	}
+
+	if len(flagImages) > 0 {
+		if err := buildImages(e, cfg, flagImages, len(args) == 2); err != nil {
+			die("%v", err)
+		}
+		return
+	}
+
+	cfg, err = offlineConfig(cfg)
+	if err != nil {
+		die("%v", err)
+	}
+
+	if err := e.Run("files", cfg); err != nil {
+		die("%v", err)
+	}
+}
+
+// validArgs returns whether args are a config and a root, or, when building
+// images, a config and an optional root.
+func validArgs(args, images []string) bool {
+	return len(args) == 2 || (len(images) > 0 && len(args) == 1)
+}
+
+// checkRoot returns an error if root isn't an existing directory.
+func checkRoot(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("couldn't access root: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("root %q is not a directory", root)
+	}
+	return nil
+}
+
+// offlineConfig returns the parts of cfg which can be applied to a directory,
+// warning about the parts which can't. It is an error for any files,
+// directories, or links to be on a filesystem other than "root", since there
+// is no block device to mount.
+func offlineConfig(cfg types.Config) (types.Config, error) {
+	if len(cfg.Storage.Disks) > 0 || len(cfg.Storage.Raid) > 0 || len(cfg.Storage.Filesystems) > 0 {
+		stderr("warning: ignoring the disks, raid, and filesystems sections of the config")
+	}
+	cfg.Storage.Disks = nil
+	cfg.Storage.Raid = nil
+	cfg.Storage.Filesystems = nil
+
+	nodes := []types.Node{}
+	for _, f := range cfg.Storage.Files {
+		nodes = append(nodes, f.Node)
+	}
+	for _, d := range cfg.Storage.Directories {
+		nodes = append(nodes, d.Node)
+	}
+	for _, l := range cfg.Storage.Links {
+		nodes = append(nodes, l.Node)
+	}
+	for _, n := range nodes {
+		if n.Filesystem != "root" {
+			return types.Config{}, fmt.Errorf("%q is on filesystem %q, but only the \"root\" filesystem can be applied", n.Path, n.Filesystem)
+		}
+	}
+
+	return cfg, nil
+}
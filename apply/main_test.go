@@ -0,0 +1,137 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestValidArgs(t *testing.T) {
+	tests := []struct {
+		args   []string
+		images []string
+		ok     bool
+	}{
+		{[]string{"config.ign", "/root"}, nil, true},
+		{[]string{"config.ign"}, nil, false},
+		{[]string{}, nil, false},
+		{[]string{"config.ign", "/root", "extra"}, nil, false},
+		{[]string{"config.ign"}, []string{"/dev/vda=disk.img"}, true},
+		{[]string{"config.ign", "/root"}, []string{"/dev/vda=disk.img"}, true},
+		{[]string{}, []string{"/dev/vda=disk.img"}, false},
+	}
+
+	for i, test := range tests {
+		if ok := validArgs(test.args, test.images); ok != test.ok {
+			t.Errorf("#%d: expected %v for %v, got %v", i, test.ok, test.args, ok)
+		}
+	}
+}
+
+func TestCheckRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition-apply-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkRoot(dir); err != nil {
+		t.Errorf("unexpected error for a directory: %v", err)
+	}
+	for _, root := range []string{file, filepath.Join(dir, "missing")} {
+		if err := checkRoot(root); err == nil {
+			t.Errorf("expected an error for %q", root)
+		}
+	}
+}
+
+func TestOfflineConfig(t *testing.T) {
+	strToPtr := func(p string) *string { return &p }
+	file := func(fs, path string) types.File {
+		return types.File{Node: types.Node{Filesystem: fs, Path: path}}
+	}
+
+	tests := []struct {
+		in  types.Config
+		out types.Config
+		ok  bool
+	}{
+		{
+			in:  types.Config{},
+			out: types.Config{},
+			ok:  true,
+		},
+		// disks, raid and filesystems are dropped, and everything else
+		// passes through
+		{
+			in: types.Config{
+				Storage: types.Storage{
+					Disks:       []types.Disk{{Device: "/dev/vda"}},
+					Raid:        []types.Raid{{Name: "md0"}},
+					Filesystems: []types.Filesystem{{Name: "root", Path: strToPtr("/sysroot")}},
+					Files:       []types.File{file("root", "/etc/motd")},
+					Directories: []types.Directory{{Node: types.Node{Filesystem: "root", Path: "/var/lib/a"}}},
+					Links:       []types.Link{{Node: types.Node{Filesystem: "root", Path: "/etc/b"}, Target: "/etc/motd"}},
+				},
+				Systemd: types.Systemd{Units: []types.Unit{{Name: "a.service", Enable: true}}},
+				Passwd:  types.Passwd{Users: []types.PasswdUser{{Name: "core"}}},
+			},
+			out: types.Config{
+				Storage: types.Storage{
+					Files:       []types.File{file("root", "/etc/motd")},
+					Directories: []types.Directory{{Node: types.Node{Filesystem: "root", Path: "/var/lib/a"}}},
+					Links:       []types.Link{{Node: types.Node{Filesystem: "root", Path: "/etc/b"}, Target: "/etc/motd"}},
+				},
+				Systemd: types.Systemd{Units: []types.Unit{{Name: "a.service", Enable: true}}},
+				Passwd:  types.Passwd{Users: []types.PasswdUser{{Name: "core"}}},
+			},
+			ok: true,
+		},
+		// nodes on other filesystems are rejected
+		{
+			in: types.Config{Storage: types.Storage{Files: []types.File{file("data", "/a")}}},
+			ok: false,
+		},
+		{
+			in: types.Config{Storage: types.Storage{Directories: []types.Directory{{Node: types.Node{Filesystem: "data", Path: "/a"}}}}},
+			ok: false,
+		},
+		{
+			in: types.Config{Storage: types.Storage{Links: []types.Link{{Node: types.Node{Filesystem: "data", Path: "/a"}}}}},
+			ok: false,
+		},
+	}
+
+	for i, test := range tests {
+		out, err := offlineConfig(test.in)
+		if (err == nil) != test.ok {
+			t.Errorf("#%d: bad error: %v", i, err)
+			continue
+		}
+		if test.ok && !reflect.DeepEqual(test.out, out) {
+			t.Errorf("#%d: bad config: want %+v, got %+v", i, test.out, out)
+		}
+	}
+}
@@ -16,7 +16,6 @@ package config
 
 import (
 	"bytes"
-	"errors"
 	"reflect"
 
 	"github.com/coreos/ignition/config/types"
@@ -33,13 +32,16 @@ import (
 )
 
 var (
-	ErrCloudConfig           = errors.New("not a config (found coreos-cloudconfig)")
-	ErrEmpty                 = errors.New("not a config (empty)")
-	ErrScript                = errors.New("not a config (found coreos-cloudinit script)")
-	ErrDeprecated            = errors.New("config format deprecated")
-	ErrInvalid               = errors.New("config is not valid")
-	ErrUnknownVersion        = errors.New("unsupported config version")
-	ErrVersionIndeterminable = errors.New("unable to determine version")
+	ErrCloudConfig           = report.NewError("IGN-CONFIG-001", "not a config (found coreos-cloudconfig)")
+	ErrEmpty                 = report.NewError("IGN-CONFIG-002", "not a config (empty)")
+	ErrScript                = report.NewError("IGN-CONFIG-003", "not a config (found coreos-cloudinit script)")
+	ErrDeprecated            = report.NewError("IGN-CONFIG-004", "config format deprecated")
+	ErrInvalid               = report.NewError("IGN-CONFIG-005", "config is not valid")
+	ErrUnknownVersion        = report.NewError("IGN-CONFIG-006", "unsupported config version")
+	ErrVersionIndeterminable = report.NewError("IGN-CONFIG-007", "unable to determine version")
+	ErrSyntax                = report.NewError("IGN-CONFIG-008", "invalid JSON syntax")
+	ErrWrongType             = report.NewError("IGN-CONFIG-009", "JSON value of the wrong type")
+	ErrNoPositions           = report.NewError("IGN-CONFIG-010", "Ignition could not unmarshal your config for reporting line numbers. This should never happen. Please file a bug.")
 )
 
 // Parse parses the raw config into a types.Config struct and generates a report of any
@@ -92,6 +94,29 @@ func Parse(rawConfig []byte) (types.Config, report.Report, error) {
 	}
 }
 
+// ParseStrict parses the raw config like Parse, but rejects configs containing
+// keys which aren't part of the spec rather than only warning about them.
+func ParseStrict(rawConfig []byte) (types.Config, report.Report, error) {
+	config, r, err := Parse(rawConfig)
+	if RejectUnknownKeys(&r) && err == nil {
+		return types.Config{}, r, ErrInvalid
+	}
+	return config, r, err
+}
+
+// RejectUnknownKeys turns the warnings in r about keys which aren't part of the
+// spec into errors, and returns whether there were any.
+func RejectUnknownKeys(r *report.Report) bool {
+	found := false
+	for i, e := range r.Entries {
+		if e.Code == report.CodeOf(validate.ErrUnrecognizedKey) {
+			r.Entries[i].Kind = report.EntryError
+			found = true
+		}
+	}
+	return found
+}
+
 func ParseFromLatest(rawConfig []byte) (types.Config, report.Report, error) {
 	var err error
 	var config types.Config
@@ -112,6 +137,7 @@ func ParseFromLatest(rawConfig []byte) (types.Config, report.Report, error) {
 				Entries: []report.Entry{{
 					Kind:      report.EntryError,
 					Message:   serr.Error(),
+					Code:      report.CodeOf(ErrSyntax),
 					Line:      line,
 					Column:    col,
 					Highlight: highlight,
@@ -127,6 +153,7 @@ func ParseFromLatest(rawConfig []byte) (types.Config, report.Report, error) {
 				Entries: []report.Entry{{
 					Kind:      report.EntryError,
 					Message:   terr.Error(),
+					Code:      report.CodeOf(ErrWrongType),
 					Line:      line,
 					Column:    col,
 					Highlight: highlight,
@@ -145,10 +172,7 @@ func ParseFromLatest(rawConfig []byte) (types.Config, report.Report, error) {
 	var r report.Report
 	configValue := reflect.ValueOf(config)
 	if err := json.Unmarshal(rawConfig, &ast); err != nil {
-		r.Add(report.Entry{
-			Kind:    report.EntryWarning,
-			Message: "Ignition could not unmarshal your config for reporting line numbers. This should never happen. Please file a bug.",
-		})
+		r.Merge(report.ReportFromError(ErrNoPositions, report.EntryWarning))
 		r.Merge(validate.ValidateWithoutSource(configValue))
 	} else {
 		r.Merge(validate.Validate(configValue, astjson.FromJsonRoot(ast), bytes.NewReader(rawConfig), true))
@@ -170,6 +194,7 @@ func ParseFromV1(rawConfig []byte) (types.Config, report.Report, error) {
 	convertedConfig := TranslateFromV1(config)
 
 	rpt := validate.ValidateWithoutSource(reflect.ValueOf(convertedConfig))
+	rpt.AddCodes()
 	if rpt.IsFatal() {
 		return types.Config{}, rpt, ErrInvalid
 	}
@@ -179,6 +204,7 @@ func ParseFromV1(rawConfig []byte) (types.Config, report.Report, error) {
 
 func ParseFromV2_0(rawConfig []byte) (types.Config, report.Report, error) {
 	cfg, report, err := v2_0.Parse(rawConfig)
+	report.AddCodes()
 	if err != nil {
 		return types.Config{}, report, err
 	}
@@ -188,6 +214,7 @@ func ParseFromV2_0(rawConfig []byte) (types.Config, report.Report, error) {
 
 func ParseFromV2_1(rawConfig []byte) (types.Config, report.Report, error) {
 	cfg, report, err := v2_1.Parse(rawConfig)
+	report.AddCodes()
 	if err != nil {
 		return types.Config{}, report, err
 	}
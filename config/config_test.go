@@ -122,3 +122,46 @@ func TestParse(t *testing.T) {
 		assert.Equal(t, test.out.config, config, "#%d: bad config, report: %+v", i, report)
 	}
 }
+
+func TestParseStrict(t *testing.T) {
+	type in struct {
+		config []byte
+	}
+	type out struct {
+		config types.Config
+		err    error
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "2.1.0"}}`)},
+			out: out{config: types.Config{Ignition: types.Ignition{Version: types.MaxVersion.String()}}},
+		},
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "2.1.0"}, "storage": {"files": [{"filesystem": "root", "path": "/a", "overwite": true}]}}`)},
+			out: out{err: ErrInvalid},
+		},
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "2.2.0-experimental"}, "systemd": {}, "sytemd": {}}`)},
+			out: out{err: ErrInvalid},
+		},
+		{
+			in:  in{config: []byte{}},
+			out: out{err: ErrEmpty},
+		},
+	}
+
+	for i, test := range tests {
+		config, report, err := ParseStrict(test.in.config)
+		if test.out.err != err {
+			t.Errorf("#%d: bad error: want %v, got %v, report: %+v", i, test.out.err, err, report)
+		}
+		if test.out.err == ErrInvalid && !report.IsFatal() {
+			t.Errorf("#%d: expected a fatal report, got %+v", i, report)
+		}
+		assert.Equal(t, test.out.config, config, "#%d: bad config, report: %+v", i, report)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint checks configs for mistakes which are valid on their own, but
+// conflict with other parts of the config or with the OS it will run on.
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+var (
+	ErrNodeKindConflict      = report.NewError("IGN-LINT-001", "path is declared as more than one kind of node")
+	ErrUnknownUser           = report.NewError("IGN-LINT-002", "user is neither created by the config nor a known system user")
+	ErrUnitWithoutContents   = report.NewError("IGN-LINT-003", "enabled unit has no contents and is not shipped by the OS")
+	ErrRaidOnPartitionedDisk = report.NewError("IGN-LINT-004", "raid array uses a disk which is also partitioned")
+)
+
+// SystemUsers are the users which exist on the OS without being created by
+// the config. Callers targeting other OSes may replace them.
+var SystemUsers = []string{
+	"root", "bin", "daemon", "adm", "lp", "news", "uucp", "operator",
+	"portage", "nobody", "core", "sshd", "dbus", "etcd", "fleet", "polkitd",
+	"tss", "systemd-bus-proxy", "systemd-coredump", "systemd-journal-gateway",
+	"systemd-journal-remote", "systemd-journal-upload", "systemd-network",
+	"systemd-resolve", "systemd-timesync",
+}
+
+// SystemUnits are the systemd units shipped by the OS. Templates are listed
+// without an instance, as in "getty@.service". Callers targeting other OSes
+// may replace them.
+var SystemUnits = []string{
+	"containerd.service", "coreos-metadata.service", "coreos-metadata-sshkeys@.service",
+	"docker.service", "docker.socket", "etcd.service", "etcd2.service",
+	"etcd-member.service", "fleet.service", "fleet.socket", "flanneld.service",
+	"getty@.service", "iscsid.service", "locksmithd.service", "ntpd.service",
+	"rkt-gc.timer", "rkt-metadata.socket", "serial-getty@.service",
+	"sshd.service", "sshd.socket", "sshd@.service", "systemd-networkd.service",
+	"systemd-resolved.service", "systemd-timesyncd.service",
+	"update-engine.service",
+}
+
+type rule func(cfg types.Config, r *report.Report)
+
+var rules = []rule{
+	checkNodeKinds,
+	checkNodeUsers,
+	checkUnitContents,
+	checkRaidDisks,
+}
+
+// Lint checks cfg for conflicts between its fields which validation doesn't
+// catch, such as a path declared as both a file and a directory. Since such
+// configs may still be what was intended, for example when another config
+// resolves the conflict, linting is opt-in. Findings are warnings, or errors if
+// strict is set.
+func Lint(cfg types.Config, strict bool) report.Report {
+	r := report.Report{}
+	for _, rule := range rules {
+		rule(cfg, &r)
+	}
+	if strict {
+		for i := range r.Entries {
+			r.Entries[i].Kind = report.EntryError
+		}
+	}
+	return r
+}
+
+func add(r *report.Report, err error, path, message string) {
+	r.Add(report.Entry{
+		Message: message,
+		Code:    report.CodeOf(err),
+		Kind:    report.EntryWarning,
+		Path:    path,
+	})
+}
+
+type nodeKey struct {
+	filesystem string
+	path       string
+}
+
+func checkNodeKinds(cfg types.Config, r *report.Report) {
+	kinds := map[nodeKey]string{}
+	check := func(kind, path string, n types.Node) {
+		key := nodeKey{n.Filesystem, filepath.Clean(n.Path)}
+		other, ok := kinds[key]
+		if !ok {
+			kinds[key] = kind
+			return
+		}
+		if other != kind {
+			add(r, ErrNodeKindConflict, path,
+				fmt.Sprintf("%q on filesystem %q is declared as both a %s and a %s", n.Path, n.Filesystem, other, kind))
+		}
+	}
+	for i, f := range cfg.Storage.Files {
+		check("file", fmt.Sprintf("storage.files.%d", i), f.Node)
+	}
+	for i, d := range cfg.Storage.Directories {
+		check("directory", fmt.Sprintf("storage.directories.%d", i), d.Node)
+	}
+	for i, l := range cfg.Storage.Links {
+		check("link", fmt.Sprintf("storage.links.%d", i), l.Node)
+	}
+}
+
+func checkNodeUsers(cfg types.Config, r *report.Report) {
+	users := map[string]struct{}{}
+	for _, u := range SystemUsers {
+		users[u] = struct{}{}
+	}
+	for _, u := range cfg.Passwd.Users {
+		users[u.Name] = struct{}{}
+	}
+	check := func(path string, n types.Node) {
+		if n.User == nil || n.User.Name == "" {
+			return
+		}
+		if _, ok := users[n.User.Name]; !ok {
+			add(r, ErrUnknownUser, path+".user.name",
+				fmt.Sprintf("user %q is neither created by the config nor a known system user", n.User.Name))
+		}
+	}
+	for i, f := range cfg.Storage.Files {
+		check(fmt.Sprintf("storage.files.%d", i), f.Node)
+	}
+	for i, d := range cfg.Storage.Directories {
+		check(fmt.Sprintf("storage.directories.%d", i), d.Node)
+	}
+	for i, l := range cfg.Storage.Links {
+		check(fmt.Sprintf("storage.links.%d", i), l.Node)
+	}
+}
+
+// isSystemUnit returns whether the unit with the given name, or the template
+// it is an instance of, is shipped by the OS.
+func isSystemUnit(name string) bool {
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at+1] + filepath.Ext(name)
+	}
+	for _, u := range SystemUnits {
+		if u == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unitDirs are the directories systemd loads units from, where the config may
+// write a unit as a file rather than through its contents.
+var unitDirs = []string{
+	"/etc/systemd/system",
+	"/usr/lib/systemd/system",
+	"/lib/systemd/system",
+}
+
+// unitFiles returns the names of the units written to the root filesystem in
+// storage.files.
+func unitFiles(cfg types.Config) map[string]struct{} {
+	units := map[string]struct{}{}
+	for _, f := range cfg.Storage.Files {
+		if f.Filesystem != "root" {
+			continue
+		}
+		dir, name := filepath.Split(filepath.Clean(f.Path))
+		for _, d := range unitDirs {
+			if filepath.Clean(dir) == d {
+				units[name] = struct{}{}
+			}
+		}
+	}
+	return units
+}
+
+// checkUnitContents warns about enabled units with no contents, unless the OS
+// ships them, storage.files writes them, or they have dropins, which may be
+// all an instance of a unit from elsewhere needs.
+func checkUnitContents(cfg types.Config, r *report.Report) {
+	files := unitFiles(cfg)
+	hasFile := func(name string) bool {
+		if _, ok := files[name]; ok {
+			return true
+		}
+		if at := strings.Index(name, "@"); at >= 0 {
+			_, ok := files[name[:at+1]+filepath.Ext(name)]
+			return ok
+		}
+		return false
+	}
+	for i, u := range cfg.Systemd.Units {
+		enabled := u.Enable || (u.Enabled != nil && *u.Enabled)
+		if !enabled || u.Mask || u.Contents != "" || len(u.Dropins) > 0 ||
+			isSystemUnit(u.Name) || hasFile(u.Name) {
+			continue
+		}
+		add(r, ErrUnitWithoutContents, fmt.Sprintf("systemd.units.%d", i),
+			fmt.Sprintf("unit %q is enabled but has no contents and is not shipped by the OS", u.Name))
+	}
+}
+
+// checkRaidDisks warns about arrays using a disk which is also partitioned.
+// Devices are compared as written, so the same disk named through different
+// paths, such as /dev/sdb and a /dev/disk/by-id link to it, isn't caught.
+func checkRaidDisks(cfg types.Config, r *report.Report) {
+	partitioned := map[string]struct{}{}
+	for _, d := range cfg.Storage.Disks {
+		if len(d.Partitions) > 0 {
+			partitioned[d.Device] = struct{}{}
+		}
+	}
+	for i, a := range cfg.Storage.Raid {
+		for j, d := range a.Devices {
+			if _, ok := partitioned[string(d)]; ok {
+				add(r, ErrRaidOnPartitionedDisk, fmt.Sprintf("storage.raid.%d.devices.%d", i, j),
+					fmt.Sprintf("array %q uses disk %q, which is also partitioned", a.Name, d))
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestLint(t *testing.T) {
+	type in struct {
+		cfg    types.Config
+		strict bool
+	}
+	type out struct {
+		r report.Report
+	}
+
+	enabled := true
+	warning := func(err error, path, message string) report.Entry {
+		return report.Entry{
+			Message: message,
+			Code:    report.CodeOf(err),
+			Kind:    report.EntryWarning,
+			Path:    path,
+		}
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{cfg: types.Config{}},
+			out: out{r: report.Report{}},
+		},
+		{
+			in: in{cfg: types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{Node: types.Node{Filesystem: "root", Path: "/etc/a"}},
+						{Node: types.Node{Filesystem: "root", Path: "/etc/b"}},
+					},
+					Directories: []types.Directory{
+						{Node: types.Node{Filesystem: "root", Path: "/etc/a/"}},
+						{Node: types.Node{Filesystem: "oem", Path: "/etc/b"}},
+					},
+				},
+			}},
+			out: out{r: report.Report{Entries: []report.Entry{
+				warning(ErrNodeKindConflict, "storage.directories.0",
+					`"/etc/a/" on filesystem "root" is declared as both a file and a directory`),
+			}}},
+		},
+		{
+			in: in{cfg: types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{Node: types.Node{Filesystem: "root", Path: "/a", User: &types.NodeUser{Name: "core"}}},
+						{Node: types.Node{Filesystem: "root", Path: "/b", User: &types.NodeUser{Name: "app"}}},
+						{Node: types.Node{Filesystem: "root", Path: "/c", User: &types.NodeUser{Name: "web"}}},
+					},
+				},
+				Passwd: types.Passwd{Users: []types.PasswdUser{{Name: "app"}}},
+			}},
+			out: out{r: report.Report{Entries: []report.Entry{
+				warning(ErrUnknownUser, "storage.files.2.user.name",
+					`user "web" is neither created by the config nor a known system user`),
+			}}},
+		},
+		{
+			in: in{cfg: types.Config{
+				Systemd: types.Systemd{Units: []types.Unit{
+					{Name: "docker.service", Enable: true},
+					{Name: "getty@tty2.service", Enabled: &enabled},
+					{Name: "app.service", Enabled: &enabled, Contents: "[Service]"},
+					{Name: "missing.service", Enable: true},
+					{Name: "masked.service", Enable: true, Mask: true},
+					{Name: "disabled.service"},
+					{Name: "file.service", Enable: true},
+					{Name: "app@1.service", Enable: true},
+					{Name: "dropin.service", Enable: true, Dropins: []types.SystemdDropin{{Name: "a.conf", Contents: "[Service]"}}},
+					{Name: "oem.service", Enable: true},
+					{Name: "elsewhere.service", Enable: true},
+				}},
+				Storage: types.Storage{Files: []types.File{
+					{Node: types.Node{Filesystem: "root", Path: "/etc/systemd/system/file.service"}},
+					{Node: types.Node{Filesystem: "root", Path: "/usr/lib/systemd/system/app@.service"}},
+					{Node: types.Node{Filesystem: "oem", Path: "/etc/systemd/system/oem.service"}},
+					{Node: types.Node{Filesystem: "root", Path: "/etc/elsewhere.service"}},
+				}},
+			}},
+			out: out{r: report.Report{Entries: []report.Entry{
+				warning(ErrUnitWithoutContents, "systemd.units.3",
+					`unit "missing.service" is enabled but has no contents and is not shipped by the OS`),
+				warning(ErrUnitWithoutContents, "systemd.units.9",
+					`unit "oem.service" is enabled but has no contents and is not shipped by the OS`),
+				warning(ErrUnitWithoutContents, "systemd.units.10",
+					`unit "elsewhere.service" is enabled but has no contents and is not shipped by the OS`),
+			}}},
+		},
+		{
+			in: in{
+				cfg: types.Config{
+					Storage: types.Storage{
+						Disks: []types.Disk{
+							{Device: "/dev/sdb", Partitions: []types.Partition{{Label: "data"}}},
+							{Device: "/dev/sdc"},
+						},
+						Raid: []types.Raid{
+							{Name: "md", Level: "raid1", Devices: []types.Device{"/dev/sdc", "/dev/sdb"}},
+						},
+					},
+				},
+				strict: true,
+			},
+			out: out{r: report.Report{Entries: []report.Entry{
+				{
+					Message: `array "md" uses disk "/dev/sdb", which is also partitioned`,
+					Code:    report.CodeOf(ErrRaidOnPartitionedDisk),
+					Kind:    report.EntryError,
+					Path:    "storage.raid.0.devices.1",
+				},
+			}}},
+		},
+	}
+
+	for i, test := range tests {
+		r := Lint(test.in.cfg, test.in.strict)
+		assert.Equal(t, test.out.r, r, "#%d: bad report", i)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+// Merge merges newConfig into oldConfig and returns the result. Unlike Append,
+// entries which describe the same object are merged rather than duplicated.
+// Files, directories and links are keyed by their filesystem and path,
+// systemd and networkd units and their dropins, passwd users and groups,
+// filesystems and raid arrays by their name, disks by their device, and
+// partitions by their number (unless it is 0, for the next available
+// partition). When both configs contain an entry with the same key, the new
+// entry is merged into the old one in place: fields the new entry leaves unset
+// keep their old values, and its lists are merged the same way. A file's
+// contents are replaced as a whole. A user's ssh keys and groups are sets, so
+// their duplicates are dropped. Everything else, including other lists of
+// plain values such as filesystem options, is appended as by Append.
+//
+// A field is unset if it holds its zero value, so a new entry can't set a
+// field which isn't a pointer back to false, 0 or "". For example, a unit
+// masked by the old config stays masked, and a file's append, a disk's
+// wipeTable, and a user's system and noCreateHome can't be turned off. Fields
+// which are pointers, such as a unit's enabled, can be.
+func Merge(oldConfig, newConfig types.Config) types.Config {
+	vOld := reflect.ValueOf(oldConfig)
+	vNew := reflect.ValueOf(newConfig)
+
+	vResult := mergeStruct(vOld, vNew)
+
+	return vResult.Interface().(types.Config)
+}
+
+// nodeKey identifies a file, directory or link.
+type nodeKey struct {
+	filesystem string
+	path       string
+}
+
+// mergeKey returns the key identifying v when merging lists, and whether v
+// has one. Members of sets are their own key.
+func mergeKey(v reflect.Value) (interface{}, bool) {
+	switch e := v.Interface().(type) {
+	case types.File:
+		return nodeKey{e.Filesystem, e.Path}, true
+	case types.Directory:
+		return nodeKey{e.Filesystem, e.Path}, true
+	case types.Link:
+		return nodeKey{e.Filesystem, e.Path}, true
+	case types.Unit:
+		return e.Name, true
+	case types.SystemdDropin:
+		return e.Name, true
+	case types.Networkdunit:
+		return e.Name, true
+	case types.NetworkdDropin:
+		return e.Name, true
+	case types.PasswdUser:
+		return e.Name, true
+	case types.PasswdGroup:
+		return e.Name, true
+	case types.Filesystem:
+		return e.Name, true
+	case types.Disk:
+		return e.Device, true
+	case types.Partition:
+		return e.Number, e.Number != 0
+	case types.Raid:
+		return e.Name, true
+	case types.SSHAuthorizedKey, types.Group, types.UsercreateGroup:
+		return e, true
+	}
+	return nil, false
+}
+
+// mergeStruct is an internal helper function to Merge. Given two values of
+// structures (assumed to be the same type), recursively iterate over every
+// field in the struct, merging slices, recursively merging structs, and
+// overwriting old values with new values that are set. As in appendStruct,
+// "ignition.version" uses the old value and "ignition.config" the new value.
+func mergeStruct(vOld, vNew reflect.Value) reflect.Value {
+	tOld := vOld.Type()
+	vRes := reflect.New(tOld)
+
+	for i := 0; i < tOld.NumField(); i++ {
+		vfOld := vOld.Field(i)
+		vfNew := vNew.Field(i)
+		vfRes := vRes.Elem().Field(i)
+
+		switch tOld.Field(i).Name {
+		case "Version":
+			vfRes.Set(vfOld)
+			continue
+		case "Config":
+			vfRes.Set(vfNew)
+			continue
+		}
+
+		switch vfOld.Type().Kind() {
+		case reflect.Struct:
+			// A file's source and verification only make sense together.
+			if vfOld.Type() == reflect.TypeOf(types.FileContents{}) && !isZero(vfNew) {
+				vfRes.Set(vfNew)
+			} else {
+				vfRes.Set(mergeStruct(vfOld, vfNew))
+			}
+		case reflect.Slice:
+			vfRes.Set(mergeSlice(vfOld, vfNew))
+		default:
+			if isZero(vfNew) {
+				vfRes.Set(vfOld)
+			} else {
+				vfRes.Set(vfNew)
+			}
+		}
+	}
+
+	return vRes.Elem()
+}
+
+// mergeSlice returns the entries of vOld followed by those of vNew, with
+// entries that share a key merged into the first of them.
+func mergeSlice(vOld, vNew reflect.Value) reflect.Value {
+	if vOld.Len() == 0 && vNew.Len() == 0 {
+		return vNew
+	}
+
+	vRes := reflect.MakeSlice(vOld.Type(), 0, vOld.Len()+vNew.Len())
+	indices := map[interface{}]int{}
+	add := func(v reflect.Value) {
+		key, ok := mergeKey(v)
+		if !ok {
+			vRes = reflect.Append(vRes, v)
+			return
+		}
+		if i, found := indices[key]; found {
+			if v.Kind() == reflect.Struct {
+				vRes.Index(i).Set(mergeStruct(vRes.Index(i), v))
+			}
+			return
+		}
+		indices[key] = vRes.Len()
+		vRes = reflect.Append(vRes, v)
+	}
+
+	for i := 0; i < vOld.Len(); i++ {
+		add(vOld.Index(i))
+	}
+	for i := 0; i < vNew.Len(); i++ {
+		add(vNew.Index(i))
+	}
+	return vRes
+}
+
+// isZero returns whether v holds the zero value of its type.
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
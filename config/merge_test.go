@@ -0,0 +1,301 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestMerge(t *testing.T) {
+	type in struct {
+		oldConfig types.Config
+		newConfig types.Config
+	}
+	type out struct {
+		config types.Config
+	}
+
+	hash := "sha512-0123"
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		// empty
+		{
+			in: in{
+				oldConfig: types.Config{},
+				newConfig: types.Config{},
+			},
+			out: out{config: types.Config{}},
+		},
+
+		// merge tags
+		{
+			in: in{
+				oldConfig: types.Config{
+					Ignition: types.Ignition{
+						Version: semver.Version{Major: 2}.String(),
+					},
+				},
+				newConfig: types.Config{
+					Ignition: types.Ignition{
+						Version: semver.Version{Major: 3}.String(),
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{
+					Version: semver.Version{Major: 2}.String(),
+				},
+			}},
+		},
+
+		// unkeyed entries are appended
+		{
+			in: in{
+				oldConfig: types.Config{
+					Ignition: types.Ignition{
+						Security: types.Security{TLS: types.TLS{
+							CertificateAuthorities: []types.CaReference{{Source: "http://a"}},
+						}},
+					},
+				},
+				newConfig: types.Config{
+					Ignition: types.Ignition{
+						Security: types.Security{TLS: types.TLS{
+							CertificateAuthorities: []types.CaReference{{Source: "http://a"}},
+						}},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{
+					Security: types.Security{TLS: types.TLS{
+						CertificateAuthorities: []types.CaReference{{Source: "http://a"}, {Source: "http://a"}},
+					}},
+				},
+			}},
+		},
+
+		// files are keyed by filesystem and path, and their contents replaced
+		{
+			in: in{
+				oldConfig: types.Config{
+					Storage: types.Storage{
+						Files: []types.File{
+							{
+								Node: types.Node{Filesystem: "root", Path: "/etc/hostname"},
+								FileEmbedded1: types.FileEmbedded1{
+									Contents: types.FileContents{
+										Source:       "http://a/hostname",
+										Verification: types.Verification{Hash: &hash},
+									},
+									Mode: intToPtr(0644),
+								},
+							},
+							{Node: types.Node{Filesystem: "oem", Path: "/etc/hostname"}},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Storage: types.Storage{
+						Files: []types.File{
+							{Node: types.Node{Filesystem: "root", Path: "/etc/motd"}},
+							{
+								Node: types.Node{Filesystem: "root", Path: "/etc/hostname"},
+								FileEmbedded1: types.FileEmbedded1{
+									Contents: types.FileContents{Source: "data:,host"},
+								},
+							},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{Filesystem: "root", Path: "/etc/hostname"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{Source: "data:,host"},
+								Mode:     intToPtr(0644),
+							},
+						},
+						{Node: types.Node{Filesystem: "oem", Path: "/etc/hostname"}},
+						{Node: types.Node{Filesystem: "root", Path: "/etc/motd"}},
+					},
+				},
+			}},
+		},
+
+		// units and their dropins are keyed by name
+		{
+			in: in{
+				oldConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{
+							{
+								Name:     "a.service",
+								Contents: "[Service]",
+								Dropins:  []types.SystemdDropin{{Name: "10-a.conf", Contents: "old"}},
+							},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{
+							{
+								Name:    "a.service",
+								Enable:  true,
+								Dropins: []types.SystemdDropin{{Name: "10-a.conf", Contents: "new"}, {Name: "20-b.conf"}},
+							},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:     "a.service",
+							Contents: "[Service]",
+							Enable:   true,
+							Dropins:  []types.SystemdDropin{{Name: "10-a.conf", Contents: "new"}, {Name: "20-b.conf"}},
+						},
+					},
+				},
+			}},
+		},
+
+		// users are keyed by name, and their ssh keys deduplicated
+		{
+			in: in{
+				oldConfig: types.Config{
+					Passwd: types.Passwd{
+						Users: []types.PasswdUser{
+							{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"a", "b"}},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Passwd: types.Passwd{
+						Users: []types.PasswdUser{
+							{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"b", "c"}},
+							{Name: "other"},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{
+						{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"a", "b", "c"}},
+						{Name: "other"},
+					},
+				},
+			}},
+		},
+
+		// disks are keyed by device
+		{
+			in: in{
+				oldConfig: types.Config{
+					Storage: types.Storage{
+						Disks: []types.Disk{
+							{Device: "/dev/sda", Partitions: []types.Partition{{Number: 1, Label: "a"}, {Label: "b"}}},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Storage: types.Storage{
+						Disks: []types.Disk{
+							{Device: "/dev/sda", WipeTable: true, Partitions: []types.Partition{{Number: 1, Size: 1024}, {Number: 2}, {Label: "b"}}},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{Device: "/dev/sda", WipeTable: true, Partitions: []types.Partition{{Number: 1, Label: "a", Size: 1024}, {Label: "b"}, {Number: 2}, {Label: "b"}}},
+					},
+				},
+			}},
+		},
+
+		// lists of plain values which aren't sets keep their repeats
+		{
+			in: in{
+				oldConfig: types.Config{
+					Storage: types.Storage{
+						Raid: []types.Raid{
+							{Name: "md0", Devices: []types.Device{"/dev/sda", "/dev/sdb"}},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Storage: types.Storage{
+						Raid: []types.Raid{
+							{Name: "md0", Options: []types.RaidOption{"-O", "a", "-O", "b"}},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Storage: types.Storage{
+					Raid: []types.Raid{
+						{Name: "md0", Devices: []types.Device{"/dev/sda", "/dev/sdb"}, Options: []types.RaidOption{"-O", "a", "-O", "b"}},
+					},
+				},
+			}},
+		},
+
+		// fields which aren't pointers can't be set back to their zero
+		// value, unlike those which are
+		{
+			in: in{
+				oldConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{{Name: "a.service", Mask: true, Enabled: &enabled}},
+					},
+				},
+				newConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{{Name: "a.service", Mask: false, Enabled: &disabled}},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Systemd: types.Systemd{
+					Units: []types.Unit{{Name: "a.service", Mask: true, Enabled: &disabled}},
+				},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		config := Merge(test.in.oldConfig, test.in.newConfig)
+		assert.Equal(t, test.out.config, config, "#%d: bad config", i)
+	}
+}
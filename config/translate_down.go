@@ -0,0 +1,605 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/coreos/ignition/config/types"
+	v2_0 "github.com/coreos/ignition/config/v2_0/types"
+	v2_1 "github.com/coreos/ignition/config/v2_1/types"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+var (
+	ErrFieldDropped = report.NewError("IGN-CONFIG-013", "field is not supported by the target spec and was dropped")
+)
+
+// droppedReporter returns a function which records in r that the field at
+// the given path can't be expressed in the given spec version.
+func droppedReporter(r *report.Report, version string) func(format string, a ...interface{}) {
+	return func(format string, a ...interface{}) {
+		r.Add(droppedEntry(fmt.Sprintf(format, a...), "field", version))
+	}
+}
+
+// droppedEntry returns a warning that the entry at path was dropped because
+// what is not supported by the given spec version.
+func droppedEntry(path, what, version string) report.Entry {
+	return report.Entry{
+		Message: fmt.Sprintf("%s is not supported by spec %s and was dropped", what, version),
+		Code:    report.CodeOf(ErrFieldDropped),
+		Kind:    report.EntryWarning,
+		Path:    path,
+	}
+}
+
+// TranslateToV2_1 translates cfg to spec 2.1. Fields which can't be expressed
+// in spec 2.1 are dropped, and the returned report has a warning for each.
+// Files which spec 2.1 would write differently, such as appended or xz
+// compressed files, and configs and files whose hash spec 2.1 can't check are
+// dropped entirely.
+func TranslateToV2_1(cfg types.Config) (v2_1.Config, report.Report) {
+	r := report.Report{}
+	return translateToV2_1(cfg, droppedReporter(&r, v2_1.MaxVersion.String())), r
+}
+
+func translateToV2_1(cfg types.Config, drop func(format string, a ...interface{})) v2_1.Config {
+	// Spec 2.1 only accepts sha512 hashes, and has no signatures. Dropping
+	// a hash, or a signature without a hash to fall back on, would leave the
+	// resource unverified, so the resource which has it is dropped instead.
+	translateVerification := func(path string, v types.Verification) (v2_1.Verification, bool) {
+		if function, _, _ := v.HashParts(); v.Hash != nil && function != "sha512" {
+			return v2_1.Verification{}, false
+		}
+		if v.Signature != nil {
+			if v.Hash == nil {
+				return v2_1.Verification{}, false
+			}
+			drop("%s.signature", path)
+		}
+		return v2_1.Verification{Hash: v.Hash}, true
+	}
+	translateConfigReference := func(path string, ref types.ConfigReference) (v2_1.ConfigReference, bool) {
+		verification, ok := translateVerification(path+".verification", ref.Verification)
+		if !ok {
+			drop("%s", path)
+			return v2_1.ConfigReference{}, false
+		}
+		if len(ref.Mirrors) > 0 {
+			drop("%s.mirrors", path)
+		}
+		if len(ref.HTTPHeaders) > 0 {
+			drop("%s.httpHeaders", path)
+		}
+		return v2_1.ConfigReference{
+			Source:       ref.Source,
+			Verification: verification,
+		}, true
+	}
+	translateNode := func(path string, n types.Node) v2_1.Node {
+		if n.Overwrite != nil {
+			drop("%s.overwrite", path)
+		}
+		node := v2_1.Node{
+			Filesystem: n.Filesystem,
+			Path:       n.Path,
+		}
+		if n.User != nil {
+			node.User = v2_1.NodeUser{ID: n.User.ID, Name: n.User.Name}
+		}
+		if n.Group != nil {
+			node.Group = v2_1.NodeGroup{ID: n.Group.ID, Name: n.Group.Name}
+		}
+		return node
+	}
+	translateMode := func(mode *int) int {
+		if mode == nil {
+			return 0
+		}
+		return *mode
+	}
+
+	config := v2_1.Config{
+		Ignition: v2_1.Ignition{
+			Version: v2_1.MaxVersion.String(),
+			Timeouts: v2_1.Timeouts{
+				HTTPResponseHeaders: cfg.Ignition.Timeouts.HTTPResponseHeaders,
+				HTTPTotal:           cfg.Ignition.Timeouts.HTTPTotal,
+			},
+		},
+	}
+
+	if cfg.Ignition.Config.Replace != nil {
+		if ref, ok := translateConfigReference("ignition.config.replace", *cfg.Ignition.Config.Replace); ok {
+			config.Ignition.Config.Replace = &ref
+		}
+	}
+	for i, ref := range cfg.Ignition.Config.Append {
+		if ref, ok := translateConfigReference(fmt.Sprintf("ignition.config.append.%d", i), ref); ok {
+			config.Ignition.Config.Append = append(config.Ignition.Config.Append, ref)
+		}
+	}
+	if len(cfg.Ignition.Security.TLS.CertificateAuthorities) > 0 {
+		drop("ignition.security.tls.certificateAuthorities")
+	}
+	if p := cfg.Ignition.Proxy; p.HTTPProxy != nil || p.HTTPSProxy != nil || len(p.NoProxy) > 0 {
+		drop("ignition.proxy")
+	}
+
+	for _, d := range cfg.Storage.Disks {
+		disk := v2_1.Disk{
+			Device:    d.Device,
+			WipeTable: d.WipeTable,
+		}
+		for _, p := range d.Partitions {
+			disk.Partitions = append(disk.Partitions, v2_1.Partition{
+				GUID:     p.GUID,
+				Label:    p.Label,
+				Number:   p.Number,
+				Size:     p.Size,
+				Start:    p.Start,
+				TypeGUID: p.TypeGUID,
+			})
+		}
+		config.Storage.Disks = append(config.Storage.Disks, disk)
+	}
+
+	for i, a := range cfg.Storage.Raid {
+		if len(a.Options) > 0 {
+			drop("storage.raid.%d.options", i)
+		}
+		array := v2_1.Raid{
+			Level:  a.Level,
+			Name:   a.Name,
+			Spares: a.Spares,
+		}
+		for _, d := range a.Devices {
+			array.Devices = append(array.Devices, v2_1.Device(d))
+		}
+		config.Storage.Raid = append(config.Storage.Raid, array)
+	}
+
+	for i, f := range cfg.Storage.Filesystems {
+		fs := v2_1.Filesystem{
+			Name: f.Name,
+			Path: f.Path,
+		}
+		if f.Mount != nil {
+			if f.Mount.MountPath != nil {
+				drop("storage.filesystems.%d.mount.mountPath", i)
+			}
+			if len(f.Mount.MountOptions) > 0 {
+				drop("storage.filesystems.%d.mount.mountOptions", i)
+			}
+			fs.Mount = &v2_1.Mount{
+				Device:         f.Mount.Device,
+				Format:         f.Mount.Format,
+				Label:          f.Mount.Label,
+				UUID:           f.Mount.UUID,
+				WipeFilesystem: f.Mount.WipeFilesystem,
+			}
+			for _, o := range f.Mount.Options {
+				fs.Mount.Options = append(fs.Mount.Options, v2_1.MountOption(o))
+			}
+			if f.Mount.Create != nil {
+				fs.Mount.Create = &v2_1.Create{Force: f.Mount.Create.Force}
+				for _, o := range f.Mount.Create.Options {
+					fs.Mount.Create.Options = append(fs.Mount.Create.Options, v2_1.CreateOption(o))
+				}
+			}
+		}
+		config.Storage.Filesystems = append(config.Storage.Filesystems, fs)
+	}
+
+	for i, f := range cfg.Storage.Files {
+		path := fmt.Sprintf("storage.files.%d", i)
+		if c := f.Contents.Compression; c != "" && c != "gzip" {
+			// spec 2.1 can't decompress the contents, so the file can't be
+			// written at all
+			drop("%s", path)
+			continue
+		}
+		if f.Append {
+			// spec 2.1 would overwrite the file instead of appending to
+			// it
+			drop("%s", path)
+			continue
+		}
+		verification, ok := translateVerification(path+".contents.verification", f.Contents.Verification)
+		if !ok {
+			drop("%s", path)
+			continue
+		}
+		if len(f.Contents.HTTPHeaders) > 0 {
+			drop("%s.contents.httpHeaders", path)
+		}
+		config.Storage.Files = append(config.Storage.Files, v2_1.File{
+			Node: translateNode(path, f.Node),
+			FileEmbedded1: v2_1.FileEmbedded1{
+				Contents: v2_1.FileContents{
+					Compression:  f.Contents.Compression,
+					Source:       f.Contents.Source,
+					Verification: verification,
+				},
+				Mode: translateMode(f.Mode),
+			},
+		})
+	}
+
+	for i, d := range cfg.Storage.Directories {
+		config.Storage.Directories = append(config.Storage.Directories, v2_1.Directory{
+			Node: translateNode(fmt.Sprintf("storage.directories.%d", i), d.Node),
+			DirectoryEmbedded1: v2_1.DirectoryEmbedded1{
+				Mode: translateMode(d.Mode),
+			},
+		})
+	}
+
+	for i, l := range cfg.Storage.Links {
+		config.Storage.Links = append(config.Storage.Links, v2_1.Link{
+			Node: translateNode(fmt.Sprintf("storage.links.%d", i), l.Node),
+			LinkEmbedded1: v2_1.LinkEmbedded1{
+				Hard:   l.Hard,
+				Target: l.Target,
+			},
+		})
+	}
+
+	for _, u := range cfg.Systemd.Units {
+		unit := v2_1.Unit{
+			Contents: u.Contents,
+			Enable:   u.Enable,
+			Enabled:  u.Enabled,
+			Mask:     u.Mask,
+			Name:     u.Name,
+		}
+		for _, d := range u.Dropins {
+			unit.Dropins = append(unit.Dropins, v2_1.Dropin{
+				Contents: d.Contents,
+				Name:     d.Name,
+			})
+		}
+		config.Systemd.Units = append(config.Systemd.Units, unit)
+	}
+
+	for i, u := range cfg.Networkd.Units {
+		if len(u.Dropins) > 0 {
+			drop("networkd.units.%d.dropins", i)
+		}
+		config.Networkd.Units = append(config.Networkd.Units, v2_1.Networkdunit{
+			Contents: u.Contents,
+			Name:     u.Name,
+		})
+	}
+
+	for _, u := range cfg.Passwd.Users {
+		user := v2_1.PasswdUser{
+			Gecos:        u.Gecos,
+			HomeDir:      u.HomeDir,
+			Name:         u.Name,
+			NoCreateHome: u.NoCreateHome,
+			NoLogInit:    u.NoLogInit,
+			NoUserGroup:  u.NoUserGroup,
+			PasswordHash: u.PasswordHash,
+			PrimaryGroup: u.PrimaryGroup,
+			Shell:        u.Shell,
+			System:       u.System,
+			UID:          u.UID,
+		}
+		for _, g := range u.Groups {
+			user.Groups = append(user.Groups, v2_1.PasswdUserGroup(g))
+		}
+		for _, k := range u.SSHAuthorizedKeys {
+			user.SSHAuthorizedKeys = append(user.SSHAuthorizedKeys, v2_1.SSHAuthorizedKey(k))
+		}
+		if u.Create != nil {
+			user.Create = &v2_1.Usercreate{
+				Gecos:        u.Create.Gecos,
+				HomeDir:      u.Create.HomeDir,
+				NoCreateHome: u.Create.NoCreateHome,
+				NoLogInit:    u.Create.NoLogInit,
+				NoUserGroup:  u.Create.NoUserGroup,
+				PrimaryGroup: u.Create.PrimaryGroup,
+				Shell:        u.Create.Shell,
+				System:       u.Create.System,
+				UID:          u.Create.UID,
+			}
+			for _, g := range u.Create.Groups {
+				user.Create.Groups = append(user.Create.Groups, v2_1.UsercreateGroup(g))
+			}
+		}
+		config.Passwd.Users = append(config.Passwd.Users, user)
+	}
+
+	for _, g := range cfg.Passwd.Groups {
+		config.Passwd.Groups = append(config.Passwd.Groups, v2_1.PasswdGroup{
+			Gid:          g.Gid,
+			Name:         g.Name,
+			PasswordHash: g.PasswordHash,
+			System:       g.System,
+		})
+	}
+
+	return config
+}
+
+// TranslateToV2_0 translates cfg to spec 2.0. Fields which can't be expressed
+// in spec 2.0 are dropped, and the returned report has a warning for each.
+// Entries which can't be expressed at all, such as directories, links, and
+// files fetched from schemes spec 2.0 doesn't support, are dropped entirely.
+func TranslateToV2_0(cfg types.Config) (v2_0.Config, report.Report) {
+	r := report.Report{}
+	drop := droppedReporter(&r, v2_0.MaxVersion.String())
+	old := translateToV2_1(cfg, drop)
+
+	// translateURL returns s as a spec 2.0 url, or a description of why it
+	// can't be expressed as one.
+	translateURL := func(s string) (v2_0.Url, string) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return v2_0.Url{}, err.Error()
+		}
+		switch u.Scheme {
+		case "", "http", "https", "oem", "data":
+			return v2_0.Url(*u), ""
+		default:
+			return v2_0.Url{}, fmt.Sprintf("scheme %q", u.Scheme)
+		}
+	}
+	translateVerification := func(path string, v v2_1.Verification) v2_0.Verification {
+		if v.Hash == nil {
+			return v2_0.Verification{}
+		}
+		var h v2_0.Hash
+		if err := json.Unmarshal([]byte(fmt.Sprintf("%q", *v.Hash)), &h); err != nil {
+			drop("%s.hash", path)
+			return v2_0.Verification{}
+		}
+		return v2_0.Verification{Hash: &h}
+	}
+	translateConfigReference := func(path string, ref v2_1.ConfigReference) (v2_0.ConfigReference, bool) {
+		source, why := translateURL(ref.Source)
+		if why != "" {
+			r.Add(droppedEntry(path, why, v2_0.MaxVersion.String()))
+			return v2_0.ConfigReference{}, false
+		}
+		return v2_0.ConfigReference{
+			Source:       source,
+			Verification: translateVerification(path+".verification", ref.Verification),
+		}, true
+	}
+	translateID := func(path string, id *int, name string) int {
+		if name != "" {
+			drop("%s.name", path)
+		}
+		if id == nil {
+			return 0
+		}
+		return *id
+	}
+	toUint := func(i *int) *uint {
+		if i == nil {
+			return nil
+		}
+		u := uint(*i)
+		return &u
+	}
+
+	config := v2_0.Config{
+		Ignition: v2_0.Ignition{
+			Version: v2_0.IgnitionVersion(v2_0.MaxVersion),
+		},
+	}
+
+	if old.Ignition.Config.Replace != nil {
+		if ref, ok := translateConfigReference("ignition.config.replace", *old.Ignition.Config.Replace); ok {
+			config.Ignition.Config.Replace = &ref
+		}
+	}
+	for i, ref := range old.Ignition.Config.Append {
+		if ref, ok := translateConfigReference(fmt.Sprintf("ignition.config.append.%d", i), ref); ok {
+			config.Ignition.Config.Append = append(config.Ignition.Config.Append, ref)
+		}
+	}
+	if old.Ignition.Timeouts != (v2_1.Timeouts{}) {
+		drop("ignition.timeouts")
+	}
+
+	for i, d := range old.Storage.Disks {
+		disk := v2_0.Disk{
+			Device:    v2_0.Path(d.Device),
+			WipeTable: d.WipeTable,
+		}
+		for j, p := range d.Partitions {
+			if p.GUID != "" {
+				drop("storage.disks.%d.partitions.%d.guid", i, j)
+			}
+			disk.Partitions = append(disk.Partitions, v2_0.Partition{
+				Label:    v2_0.PartitionLabel(p.Label),
+				Number:   p.Number,
+				Size:     v2_0.PartitionDimension(p.Size),
+				Start:    v2_0.PartitionDimension(p.Start),
+				TypeGUID: v2_0.PartitionTypeGUID(p.TypeGUID),
+			})
+		}
+		config.Storage.Disks = append(config.Storage.Disks, disk)
+	}
+
+	for _, a := range old.Storage.Raid {
+		array := v2_0.Raid{
+			Level:  a.Level,
+			Name:   a.Name,
+			Spares: a.Spares,
+		}
+		for _, d := range a.Devices {
+			array.Devices = append(array.Devices, v2_0.Path(d))
+		}
+		config.Storage.Arrays = append(config.Storage.Arrays, array)
+	}
+
+	for i, f := range old.Storage.Filesystems {
+		fs := v2_0.Filesystem{Name: f.Name}
+		if f.Path != nil {
+			p := v2_0.Path(*f.Path)
+			fs.Path = &p
+		}
+		if f.Mount != nil {
+			path := fmt.Sprintf("storage.filesystems.%d.mount", i)
+			if f.Mount.Label != nil {
+				drop("%s.label", path)
+			}
+			if f.Mount.UUID != nil {
+				drop("%s.uuid", path)
+			}
+			if f.Mount.WipeFilesystem {
+				drop("%s.wipeFilesystem", path)
+			}
+			if len(f.Mount.Options) > 0 {
+				drop("%s.options", path)
+			}
+			fs.Mount = &v2_0.FilesystemMount{
+				Device: v2_0.Path(f.Mount.Device),
+				Format: v2_0.FilesystemFormat(f.Mount.Format),
+			}
+			if f.Mount.Create != nil {
+				fs.Mount.Create = &v2_0.FilesystemCreate{Force: f.Mount.Create.Force}
+				for _, o := range f.Mount.Create.Options {
+					fs.Mount.Create.Options = append(fs.Mount.Create.Options, string(o))
+				}
+			}
+		}
+		config.Storage.Filesystems = append(config.Storage.Filesystems, fs)
+	}
+
+	for i, f := range old.Storage.Files {
+		path := fmt.Sprintf("storage.files.%d", i)
+		source, why := translateURL(f.Contents.Source)
+		if why != "" {
+			r.Add(droppedEntry(path, why, v2_0.MaxVersion.String()))
+			continue
+		}
+		config.Storage.Files = append(config.Storage.Files, v2_0.File{
+			Filesystem: f.Filesystem,
+			Path:       v2_0.Path(f.Path),
+			Contents: v2_0.FileContents{
+				Compression:  v2_0.Compression(f.Contents.Compression),
+				Source:       source,
+				Verification: translateVerification(path+".contents.verification", f.Contents.Verification),
+			},
+			Mode:  v2_0.FileMode(os.FileMode(f.Mode)),
+			User:  v2_0.FileUser{Id: translateID(path+".user", f.User.ID, f.User.Name)},
+			Group: v2_0.FileGroup{Id: translateID(path+".group", f.Group.ID, f.Group.Name)},
+		})
+	}
+	for i := range old.Storage.Directories {
+		drop("storage.directories.%d", i)
+	}
+	for i := range old.Storage.Links {
+		drop("storage.links.%d", i)
+	}
+
+	for i, u := range old.Systemd.Units {
+		unit := v2_0.SystemdUnit{
+			Name:     v2_0.SystemdUnitName(u.Name),
+			Enable:   u.Enable,
+			Mask:     u.Mask,
+			Contents: u.Contents,
+		}
+		if u.Enabled != nil {
+			if *u.Enabled {
+				unit.Enable = true
+			} else {
+				drop("systemd.units.%d.enabled", i)
+			}
+		}
+		for _, d := range u.Dropins {
+			unit.DropIns = append(unit.DropIns, v2_0.SystemdUnitDropIn{
+				Name:     v2_0.SystemdUnitDropInName(d.Name),
+				Contents: d.Contents,
+			})
+		}
+		config.Systemd.Units = append(config.Systemd.Units, unit)
+	}
+
+	for _, u := range old.Networkd.Units {
+		config.Networkd.Units = append(config.Networkd.Units, v2_0.NetworkdUnit{
+			Name:     v2_0.NetworkdUnitName(u.Name),
+			Contents: u.Contents,
+		})
+	}
+
+	for i, u := range old.Passwd.Users {
+		path := fmt.Sprintf("passwd.users.%d", i)
+		user := v2_0.User{Name: u.Name}
+		if u.PasswordHash != nil {
+			user.PasswordHash = *u.PasswordHash
+		}
+		for _, k := range u.SSHAuthorizedKeys {
+			user.SSHAuthorizedKeys = append(user.SSHAuthorizedKeys, string(k))
+		}
+		// Spec 2.0 can only create users, through the create section.
+		for _, field := range []struct {
+			name string
+			set  bool
+		}{
+			{"gecos", u.Gecos != ""},
+			{"groups", len(u.Groups) > 0},
+			{"homeDir", u.HomeDir != ""},
+			{"noCreateHome", u.NoCreateHome},
+			{"noLogInit", u.NoLogInit},
+			{"noUserGroup", u.NoUserGroup},
+			{"primaryGroup", u.PrimaryGroup != ""},
+			{"shell", u.Shell != ""},
+			{"system", u.System},
+			{"uid", u.UID != nil},
+		} {
+			if field.set {
+				drop("%s.%s", path, field.name)
+			}
+		}
+		if u.Create != nil {
+			user.Create = &v2_0.UserCreate{
+				Uid:          toUint(u.Create.UID),
+				GECOS:        u.Create.Gecos,
+				Homedir:      u.Create.HomeDir,
+				NoCreateHome: u.Create.NoCreateHome,
+				PrimaryGroup: u.Create.PrimaryGroup,
+				NoUserGroup:  u.Create.NoUserGroup,
+				System:       u.Create.System,
+				NoLogInit:    u.Create.NoLogInit,
+				Shell:        u.Create.Shell,
+			}
+			for _, g := range u.Create.Groups {
+				user.Create.Groups = append(user.Create.Groups, string(g))
+			}
+		}
+		config.Passwd.Users = append(config.Passwd.Users, user)
+	}
+
+	for _, g := range old.Passwd.Groups {
+		config.Passwd.Groups = append(config.Passwd.Groups, v2_0.Group{
+			Name:         g.Name,
+			Gid:          toUint(g.Gid),
+			PasswordHash: g.PasswordHash,
+			System:       g.System,
+		})
+	}
+
+	return config, r
+}
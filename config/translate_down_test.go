@@ -0,0 +1,334 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coreos/ignition/config/types"
+	v2_0 "github.com/coreos/ignition/config/v2_0/types"
+	v2_1 "github.com/coreos/ignition/config/v2_1/types"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func dropped(version string, paths ...string) report.Report {
+	r := report.Report{}
+	for _, p := range paths {
+		r.Add(report.Entry{
+			Message: "field is not supported by spec " + version + " and was dropped",
+			Code:    report.CodeOf(ErrFieldDropped),
+			Kind:    report.EntryWarning,
+			Path:    p,
+		})
+	}
+	return r
+}
+
+func TestTranslateToV2_1(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		config v2_1.Config
+		r      report.Report
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{config: types.Config{}},
+			out: out{config: v2_1.Config{
+				Ignition: v2_1.Ignition{Version: "2.1.0"},
+			}},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{
+					Config: types.IgnitionConfig{
+						Replace: &types.ConfigReference{
+							Source:  "http://a/config.ign",
+							Mirrors: []types.ConfigReferenceMirror{"http://b/config.ign"},
+						},
+					},
+				},
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{Filesystem: "root", Path: "/a", Overwrite: boolToPtr(false)},
+							FileEmbedded1: types.FileEmbedded1{
+								Append:   true,
+								Contents: types.FileContents{Source: "data:,a"},
+							},
+						},
+						{
+							Node: types.Node{
+								Filesystem: "root",
+								Path:       "/b",
+								User:       &types.NodeUser{Name: "core"},
+							},
+							FileEmbedded1: types.FileEmbedded1{Mode: intToPtr(0600)},
+						},
+					},
+					Directories: []types.Directory{
+						{Node: types.Node{Filesystem: "root", Path: "/c"}},
+					},
+				},
+				Networkd: types.Networkd{
+					Units: []types.Networkdunit{
+						{Name: "a.network", Dropins: []types.NetworkdDropin{{Name: "a.conf"}}},
+					},
+				},
+			}},
+			out: out{
+				config: v2_1.Config{
+					Ignition: v2_1.Ignition{
+						Version: "2.1.0",
+						Config: v2_1.IgnitionConfig{
+							Replace: &v2_1.ConfigReference{Source: "http://a/config.ign"},
+						},
+					},
+					Storage: v2_1.Storage{
+						Files: []v2_1.File{
+							{
+								Node: v2_1.Node{
+									Filesystem: "root",
+									Path:       "/b",
+									User:       v2_1.NodeUser{Name: "core"},
+								},
+								FileEmbedded1: v2_1.FileEmbedded1{Mode: 0600},
+							},
+						},
+						Directories: []v2_1.Directory{
+							{Node: v2_1.Node{Filesystem: "root", Path: "/c"}},
+						},
+					},
+					Networkd: v2_1.Networkd{
+						Units: []v2_1.Networkdunit{{Name: "a.network"}},
+					},
+				},
+				r: dropped("2.1.0",
+					"ignition.config.replace.mirrors",
+					"storage.files.0",
+					"networkd.units.0.dropins",
+				),
+			},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{
+					Config: types.IgnitionConfig{
+						Append: []types.ConfigReference{
+							{Source: "http://a/config.ign", Verification: types.Verification{Hash: strToPtr("sha512-0123")}, HTTPHeaders: []types.HTTPHeader{{Name: "Authorization", Value: strToPtr("Bearer abc")}}},
+							{Source: "http://b/config.ign", Verification: types.Verification{Hash: strToPtr("sha256-0123")}},
+							{Source: "http://c/config.ign", Verification: types.Verification{Signature: strToPtr("http://c/config.ign.sig")}},
+							{Source: "http://d/config.ign", Verification: types.Verification{Hash: strToPtr("sha512-0123"), Signature: strToPtr("http://d/config.ign.sig")}},
+						},
+					},
+					Proxy: types.Proxy{NoProxy: []types.NoProxyItem{"example.com"}},
+				},
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{Filesystem: "root", Path: "/a"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{Source: "http://a/b", Verification: types.Verification{Hash: strToPtr("sha384-0123")}},
+							},
+						},
+						{
+							Node: types.Node{Filesystem: "root", Path: "/c"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{Source: "http://a/c.xz", Compression: "xz"},
+							},
+						},
+						{
+							Node: types.Node{Filesystem: "root", Path: "/d"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{Source: "http://a/d", Verification: types.Verification{Hash: strToPtr("sha512-0123")}, HTTPHeaders: []types.HTTPHeader{{Name: "User-Agent"}}},
+							},
+						},
+					},
+				},
+			}},
+			out: out{
+				config: v2_1.Config{
+					Ignition: v2_1.Ignition{
+						Version: "2.1.0",
+						Config: v2_1.IgnitionConfig{
+							Append: []v2_1.ConfigReference{
+								{Source: "http://a/config.ign", Verification: v2_1.Verification{Hash: strToPtr("sha512-0123")}},
+								{Source: "http://d/config.ign", Verification: v2_1.Verification{Hash: strToPtr("sha512-0123")}},
+							},
+						},
+					},
+					Storage: v2_1.Storage{
+						Files: []v2_1.File{
+							{
+								Node: v2_1.Node{Filesystem: "root", Path: "/d"},
+								FileEmbedded1: v2_1.FileEmbedded1{
+									Contents: v2_1.FileContents{Source: "http://a/d", Verification: v2_1.Verification{Hash: strToPtr("sha512-0123")}},
+								},
+							},
+						},
+					},
+				},
+				r: dropped("2.1.0",
+					"ignition.config.append.0.httpHeaders",
+					"ignition.config.append.1",
+					"ignition.config.append.2",
+					"ignition.config.append.3.verification.signature",
+					"ignition.proxy",
+					"storage.files.0",
+					"storage.files.1",
+					"storage.files.2.contents.httpHeaders",
+				),
+			},
+		},
+	}
+
+	for i, test := range tests {
+		config, r := TranslateToV2_1(test.in.config)
+		assert.Equal(t, test.out.config, config, "#%d: bad config", i)
+		assert.Equal(t, test.out.r, r, "#%d: bad report", i)
+	}
+}
+
+func TestTranslateToV2_0(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		config v2_0.Config
+		r      report.Report
+	}
+
+	hash := "sha512-0123"
+	source, _ := url.Parse("http://a/b")
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{config: types.Config{}},
+			out: out{config: v2_0.Config{
+				Ignition: v2_0.Ignition{Version: v2_0.IgnitionVersion(v2_0.MaxVersion)},
+			}},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{
+					Timeouts: types.Timeouts{HTTPTotal: intToPtr(10)},
+				},
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{
+								Filesystem: "root",
+								Path:       "/a",
+								User:       &types.NodeUser{ID: intToPtr(500), Name: "core"},
+							},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{
+									Source:       "http://a/b",
+									Verification: types.Verification{Hash: &hash},
+								},
+							},
+						},
+						{
+							Node: types.Node{Filesystem: "root", Path: "/b"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{Source: "s3://a/b"},
+							},
+						},
+					},
+					Links: []types.Link{
+						{Node: types.Node{Filesystem: "root", Path: "/c"}},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{Name: "a.service", Enabled: boolToPtr(true)},
+						{Name: "b.service", Enabled: boolToPtr(false)},
+					},
+				},
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{
+						{Name: "core", Shell: "/bin/zsh", PasswordHash: strToPtr("x")},
+					},
+				},
+			}},
+			out: out{
+				config: v2_0.Config{
+					Ignition: v2_0.Ignition{Version: v2_0.IgnitionVersion(v2_0.MaxVersion)},
+					Storage: v2_0.Storage{
+						Files: []v2_0.File{
+							{
+								Filesystem: "root",
+								Path:       "/a",
+								Contents: v2_0.FileContents{
+									Source: v2_0.Url(*source),
+									Verification: v2_0.Verification{
+										Hash: &v2_0.Hash{Function: "sha512", Sum: "0123"},
+									},
+								},
+								User: v2_0.FileUser{Id: 500},
+							},
+						},
+					},
+					Systemd: v2_0.Systemd{
+						Units: []v2_0.SystemdUnit{
+							{Name: "a.service", Enable: true},
+							{Name: "b.service"},
+						},
+					},
+					Passwd: v2_0.Passwd{
+						Users: []v2_0.User{
+							{Name: "core", PasswordHash: "x"},
+						},
+					},
+				},
+				r: func() report.Report {
+					r := dropped("2.0.0",
+						"ignition.timeouts",
+						"storage.files.0.user.name",
+					)
+					r.Add(report.Entry{
+						Message: `scheme "s3" is not supported by spec 2.0.0 and was dropped`,
+						Code:    report.CodeOf(ErrFieldDropped),
+						Kind:    report.EntryWarning,
+						Path:    "storage.files.1",
+					})
+					r.Merge(dropped("2.0.0",
+						"storage.links.0",
+						"systemd.units.1.enabled",
+						"passwd.users.0.shell",
+					))
+					return r
+				}(),
+			},
+		},
+	}
+
+	for i, test := range tests {
+		config, r := TranslateToV2_0(test.in.config)
+		assert.Equal(t, test.out.config, config, "#%d: bad config", i)
+		assert.Equal(t, test.out.r, r, "#%d: bad report", i)
+	}
+}
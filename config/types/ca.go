@@ -21,11 +21,14 @@ import (
 )
 
 func (c CaReference) ValidateSource() report.Report {
+	r := report.Report{}
 	err := validateURL(c.Source)
 	if err != nil {
-		return report.ReportFromError(
-			fmt.Errorf("invalid url %q: %v", c.Source, err),
-			report.EntryError)
+		r.Add(report.Entry{
+			Message: fmt.Sprintf("invalid url %q: %v", c.Source, err),
+			Code:    urlErrorCode(err),
+			Kind:    report.EntryError,
+		})
 	}
-	return report.Report{}
+	return r
 }
@@ -43,6 +43,11 @@ func (c Config) Validate() report.Report {
 	return r
 }
 
+var (
+	ErrNonexistentFilesystem = report.NewError("IGN-STORAGE-030", "node references a nonexistent filesystem")
+	ErrShadowedFilesystem    = report.NewError("IGN-STORAGE-031", "filesystem shadows an existing filesystem definition")
+)
+
 type rule func(cfg Config, report *report.Report)
 
 func checkNodeFilesystems(node Node, filesystems map[string]struct{}, nodeType string) report.Report {
@@ -57,6 +62,7 @@ func checkNodeFilesystems(node Node, filesystems map[string]struct{}, nodeType s
 			Kind: report.EntryWarning,
 			Message: fmt.Sprintf("%v %q references nonexistent filesystem %q. (This is ok if it is defined in a referenced config)",
 				nodeType, node.Path, node.Filesystem),
+			Code: report.CodeOf(ErrNonexistentFilesystem),
 		})
 	}
 	return r
@@ -85,6 +91,7 @@ func checkDuplicateFilesystems(cfg Config, r *report.Report) {
 			r.Add(report.Entry{
 				Kind:    report.EntryWarning,
 				Message: fmt.Sprintf("Filesystem %q shadows exising filesystem definition", filesystem.Name),
+				Code:    report.CodeOf(ErrShadowedFilesystem),
 			})
 		}
 		filesystems[filesystem.Name] = struct{}{}
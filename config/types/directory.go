@@ -18,17 +18,23 @@ import (
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+var (
+	ErrDirectoryModeUnset = report.NewError("IGN-STORAGE-021", "directory permissions unset, defaulting to 0000")
+)
+
 func (d Directory) ValidateMode() report.Report {
 	r := report.Report{}
 	if err := validateMode(d.Mode); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(err),
 			Kind:    report.EntryError,
 		})
 	}
 	if d.Mode == nil {
 		r.Add(report.Entry{
-			Message: "directory permissions unset, defaulting to 0000",
+			Message: ErrDirectoryModeUnset.Error(),
+			Code:    report.CodeOf(ErrDirectoryModeUnset),
 			Kind:    report.EntryWarning,
 		})
 	}
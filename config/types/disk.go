@@ -20,13 +20,20 @@ import (
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+var (
+	ErrDiskDeviceRequired      = report.NewError("IGN-STORAGE-024", "disk device is required")
+	ErrPartitionNumbersCollide = report.NewError("IGN-STORAGE-025", "partition numbers collide")
+	ErrPartitionsOverlap       = report.NewError("IGN-STORAGE-026", "partitions overlap")
+	ErrPartitionsMisaligned    = report.NewError("IGN-STORAGE-027", "partitions misaligned")
+)
+
 func (n Disk) Validate() report.Report {
 	return report.Report{}
 }
 
 func (n Disk) ValidateDevice() report.Report {
 	if len(n.Device) == 0 {
-		return report.ReportFromError(fmt.Errorf("disk device is required"), report.EntryError)
+		return report.ReportFromError(ErrDiskDeviceRequired, report.EntryError)
 	}
 	if err := validatePath(string(n.Device)); err != nil {
 		return report.ReportFromError(err, report.EntryError)
@@ -38,19 +45,22 @@ func (n Disk) ValidatePartitions() report.Report {
 	r := report.Report{}
 	if n.partitionNumbersCollide() {
 		r.Add(report.Entry{
-			Message: fmt.Sprintf("disk %q: partition numbers collide", n.Device),
+			Message: fmt.Sprintf("disk %q: %v", n.Device, ErrPartitionNumbersCollide),
+			Code:    report.CodeOf(ErrPartitionNumbersCollide),
 			Kind:    report.EntryError,
 		})
 	}
 	if n.partitionsOverlap() {
 		r.Add(report.Entry{
-			Message: fmt.Sprintf("disk %q: partitions overlap", n.Device),
+			Message: fmt.Sprintf("disk %q: %v", n.Device, ErrPartitionsOverlap),
+			Code:    report.CodeOf(ErrPartitionsOverlap),
 			Kind:    report.EntryError,
 		})
 	}
 	if n.partitionsMisaligned() {
 		r.Add(report.Entry{
-			Message: fmt.Sprintf("disk %q: partitions misaligned", n.Device),
+			Message: fmt.Sprintf("disk %q: %v", n.Device, ErrPartitionsMisaligned),
+			Code:    report.CodeOf(ErrPartitionsMisaligned),
 			Kind:    report.EntryError,
 		})
 	}
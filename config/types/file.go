@@ -15,15 +15,15 @@
 package types
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrAppendAndOverwrite = errors.New("cannot set both append and overwrite to true")
-	ErrCompressionInvalid = errors.New("invalid compression method")
+	ErrAppendAndOverwrite = report.NewError("IGN-STORAGE-018", "cannot set both append and overwrite to true")
+	ErrCompressionInvalid = report.NewError("IGN-STORAGE-019", "invalid compression method")
+	ErrFileModeUnset      = report.NewError("IGN-STORAGE-020", "file permissions unset, defaulting to 0000")
 )
 
 func (f File) Validate() report.Report {
@@ -38,12 +38,14 @@ func (f File) ValidateMode() report.Report {
 	if err := validateMode(f.Mode); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(err),
 			Kind:    report.EntryError,
 		})
 	}
 	if f.Mode == nil {
 		r.Add(report.Entry{
-			Message: "file permissions unset, defaulting to 0000",
+			Message: ErrFileModeUnset.Error(),
+			Code:    report.CodeOf(ErrFileModeUnset),
 			Kind:    report.EntryWarning,
 		})
 	}
@@ -53,10 +55,11 @@ func (f File) ValidateMode() report.Report {
 func (fc FileContents) ValidateCompression() report.Report {
 	r := report.Report{}
 	switch fc.Compression {
-	case "", "gzip":
+	case "", "gzip", "bzip2", "xz", "zstd":
 	default:
 		r.Add(report.Entry{
 			Message: ErrCompressionInvalid.Error(),
+			Code:    report.CodeOf(ErrCompressionInvalid),
 			Kind:    report.EntryError,
 		})
 	}
@@ -69,6 +72,7 @@ func (fc FileContents) ValidateSource() report.Report {
 	if err != nil {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("invalid url %q: %v", fc.Source, err),
+			Code:    urlErrorCode(err),
 			Kind:    report.EntryError,
 		})
 	}
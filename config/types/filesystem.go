@@ -15,24 +15,26 @@
 package types
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrFilesystemInvalidFormat     = errors.New("invalid filesystem format")
-	ErrFilesystemNoMountPath       = errors.New("filesystem is missing mount or path")
-	ErrFilesystemMountAndPath      = errors.New("filesystem has both mount and path defined")
-	ErrUsedCreateAndMountOpts      = errors.New("cannot use both create object and mount-level options field")
-	ErrUsedCreateAndWipeFilesystem = errors.New("cannot use both create object and wipeFilesystem field")
-	ErrWarningCreateDeprecated     = errors.New("the create object has been deprecated in favor of mount-level options")
-	ErrExt4LabelTooLong            = errors.New("filesystem labels cannot be longer than 16 characters when using ext4")
-	ErrBtrfsLabelTooLong           = errors.New("filesystem labels cannot be longer than 256 characters when using btrfs")
-	ErrXfsLabelTooLong             = errors.New("filesystem labels cannot be longer than 12 characters when using xfs")
-	ErrSwapLabelTooLong            = errors.New("filesystem labels cannot be longer than 15 characters when using swap")
-	ErrVfatLabelTooLong            = errors.New("filesystem labels cannot be longer than 11 characters when using vfat")
+	ErrFilesystemInvalidFormat     = report.NewError("IGN-STORAGE-001", "invalid filesystem format")
+	ErrFilesystemNoMountPath       = report.NewError("IGN-STORAGE-002", "filesystem is missing mount or path")
+	ErrFilesystemMountAndPath      = report.NewError("IGN-STORAGE-003", "filesystem has both mount and path defined")
+	ErrUsedCreateAndMountOpts      = report.NewError("IGN-STORAGE-004", "cannot use both create object and mount-level options field")
+	ErrUsedCreateAndWipeFilesystem = report.NewError("IGN-STORAGE-005", "cannot use both create object and wipeFilesystem field")
+	ErrWarningCreateDeprecated     = report.NewError("IGN-STORAGE-006", "the create object has been deprecated in favor of mount-level options")
+	ErrExt4LabelTooLong            = report.NewError("IGN-STORAGE-007", "filesystem labels cannot be longer than 16 characters when using ext4")
+	ErrBtrfsLabelTooLong           = report.NewError("IGN-STORAGE-008", "filesystem labels cannot be longer than 256 characters when using btrfs")
+	ErrXfsLabelTooLong             = report.NewError("IGN-STORAGE-009", "filesystem labels cannot be longer than 12 characters when using xfs")
+	ErrSwapLabelTooLong            = report.NewError("IGN-STORAGE-010", "filesystem labels cannot be longer than 15 characters when using swap")
+	ErrVfatLabelTooLong            = report.NewError("IGN-STORAGE-011", "filesystem labels cannot be longer than 11 characters when using vfat")
+	ErrSwapMountPath               = report.NewError("IGN-STORAGE-015", "swap filesystems cannot have a mountPath")
+	ErrMountOptionsWithoutPath     = report.NewError("IGN-STORAGE-016", "mountOptions are only used when a mountPath is set")
+	ErrFilesystemPathRelative      = report.NewError("IGN-STORAGE-017", "filesystem path not absolute")
 )
 
 func (f Filesystem) Validate() report.Report {
@@ -40,6 +42,7 @@ func (f Filesystem) Validate() report.Report {
 	if f.Mount == nil && f.Path == nil {
 		r.Add(report.Entry{
 			Message: ErrFilesystemNoMountPath.Error(),
+			Code:    report.CodeOf(ErrFilesystemNoMountPath),
 			Kind:    report.EntryError,
 		})
 	}
@@ -47,6 +50,7 @@ func (f Filesystem) Validate() report.Report {
 		if f.Path != nil {
 			r.Add(report.Entry{
 				Message: ErrFilesystemMountAndPath.Error(),
+				Code:    report.CodeOf(ErrFilesystemMountAndPath),
 				Kind:    report.EntryError,
 			})
 		}
@@ -54,17 +58,20 @@ func (f Filesystem) Validate() report.Report {
 			if f.Mount.WipeFilesystem {
 				r.Add(report.Entry{
 					Message: ErrUsedCreateAndWipeFilesystem.Error(),
+					Code:    report.CodeOf(ErrUsedCreateAndWipeFilesystem),
 					Kind:    report.EntryError,
 				})
 			}
 			if len(f.Mount.Options) > 0 {
 				r.Add(report.Entry{
 					Message: ErrUsedCreateAndMountOpts.Error(),
+					Code:    report.CodeOf(ErrUsedCreateAndMountOpts),
 					Kind:    report.EntryError,
 				})
 			}
 			r.Add(report.Entry{
 				Message: ErrWarningCreateDeprecated.Error(),
+				Code:    report.CodeOf(ErrWarningCreateDeprecated),
 				Kind:    report.EntryWarning,
 			})
 		}
@@ -77,6 +84,7 @@ func (f Filesystem) ValidatePath() report.Report {
 	if f.Path != nil && validatePath(*f.Path) != nil {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("filesystem %q: path not absolute", f.Name),
+			Code:    report.CodeOf(ErrFilesystemPathRelative),
 			Kind:    report.EntryError,
 		})
 	}
@@ -90,6 +98,36 @@ func (m Mount) Validate() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: ErrFilesystemInvalidFormat.Error(),
+			Code:    report.CodeOf(ErrFilesystemInvalidFormat),
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
+
+func (m Mount) ValidateMountPath() report.Report {
+	r := report.Report{}
+	if m.MountPath == nil {
+		if len(m.MountOptions) > 0 {
+			r.Add(report.Entry{
+				Message: ErrMountOptionsWithoutPath.Error(),
+				Code:    report.CodeOf(ErrMountOptionsWithoutPath),
+				Kind:    report.EntryWarning,
+			})
+		}
+		return r
+	}
+	if err := validatePath(*m.MountPath); err != nil {
+		r.Add(report.Entry{
+			Message: err.Error(),
+			Code:    report.CodeOf(err),
+			Kind:    report.EntryError,
+		})
+	}
+	if m.Format == "swap" {
+		r.Add(report.Entry{
+			Message: ErrSwapMountPath.Error(),
+			Code:    report.CodeOf(ErrSwapMountPath),
 			Kind:    report.EntryError,
 		})
 	}
@@ -101,6 +139,7 @@ func (m Mount) ValidateDevice() report.Report {
 	if err := validatePath(m.Device); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(err),
 			Kind:    report.EntryError,
 		})
 	}
@@ -118,6 +157,7 @@ func (m Mount) ValidateLabel() report.Report {
 			// source: man mkfs.ext4
 			r.Add(report.Entry{
 				Message: ErrExt4LabelTooLong.Error(),
+				Code:    report.CodeOf(ErrExt4LabelTooLong),
 				Kind:    report.EntryError,
 			})
 		}
@@ -126,6 +166,7 @@ func (m Mount) ValidateLabel() report.Report {
 			// source: man mkfs.btrfs
 			r.Add(report.Entry{
 				Message: ErrBtrfsLabelTooLong.Error(),
+				Code:    report.CodeOf(ErrBtrfsLabelTooLong),
 				Kind:    report.EntryError,
 			})
 		}
@@ -134,6 +175,7 @@ func (m Mount) ValidateLabel() report.Report {
 			// source: man mkfs.xfs
 			r.Add(report.Entry{
 				Message: ErrXfsLabelTooLong.Error(),
+				Code:    report.CodeOf(ErrXfsLabelTooLong),
 				Kind:    report.EntryError,
 			})
 		}
@@ -144,6 +186,7 @@ func (m Mount) ValidateLabel() report.Report {
 		if len(*m.Label) > 15 {
 			r.Add(report.Entry{
 				Message: ErrSwapLabelTooLong.Error(),
+				Code:    report.CodeOf(ErrSwapLabelTooLong),
 				Kind:    report.EntryError,
 			})
 		}
@@ -152,6 +195,7 @@ func (m Mount) ValidateLabel() report.Report {
 			// source: man mkfs.fat
 			r.Add(report.Entry{
 				Message: ErrVfatLabelTooLong.Error(),
+				Code:    report.CodeOf(ErrVfatLabelTooLong),
 				Kind:    report.EntryError,
 			})
 		}
@@ -176,3 +176,36 @@ func TestLabelValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestMountValidateMountPath(t *testing.T) {
+	strp := func(s string) *string { return &s }
+
+	tests := []struct {
+		in  Mount
+		out report.Report
+	}{
+		{
+			in:  Mount{Format: "ext4", MountPath: strp("/var")},
+			out: report.Report{},
+		},
+		{
+			in:  Mount{Format: "ext4", MountPath: strp("var")},
+			out: report.ReportFromError(ErrPathRelative, report.EntryError),
+		},
+		{
+			in:  Mount{Format: "swap", MountPath: strp("/swap")},
+			out: report.ReportFromError(ErrSwapMountPath, report.EntryError),
+		},
+		{
+			in:  Mount{Format: "ext4", MountOptions: []MountMountOption{"noatime"}},
+			out: report.ReportFromError(ErrMountOptionsWithoutPath, report.EntryWarning),
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.ValidateMountPath()
+		if !reflect.DeepEqual(test.out, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out, r)
+		}
+	}
+}
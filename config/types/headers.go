@@ -0,0 +1,99 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+var (
+	ErrInvalidHeaderName  = report.NewError("IGN-COMMON-008", "invalid http header name")
+	ErrForbiddenHeader    = report.NewError("IGN-COMMON-009", "http header can't be set")
+	ErrDuplicateHeader    = report.NewError("IGN-COMMON-010", "duplicate http header")
+	ErrInvalidHeaderValue = report.NewError("IGN-COMMON-011", "invalid http header value")
+)
+
+// forbiddenHeaders are managed by the http client itself, so setting them
+// would break the request rather than customize it.
+var forbiddenHeaders = map[string]bool{
+	"Accept-Encoding":   true,
+	"Connection":        true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+func (c ConfigReference) ValidateHTTPHeaders() report.Report {
+	return validateHTTPHeaders(c.HTTPHeaders)
+}
+
+func (c CaReference) ValidateHTTPHeaders() report.Report {
+	return validateHTTPHeaders(c.HTTPHeaders)
+}
+
+func (fc FileContents) ValidateHTTPHeaders() report.Report {
+	return validateHTTPHeaders(fc.HTTPHeaders)
+}
+
+func validateHTTPHeaders(headers []HTTPHeader) report.Report {
+	r := report.Report{}
+	seen := map[string]bool{}
+	for _, h := range headers {
+		name := http.CanonicalHeaderKey(h.Name)
+		switch {
+		case !isToken(h.Name):
+			r.Add(headerEntry(ErrInvalidHeaderName, h.Name))
+		case forbiddenHeaders[name]:
+			r.Add(headerEntry(ErrForbiddenHeader, h.Name))
+		case seen[name]:
+			r.Add(headerEntry(ErrDuplicateHeader, h.Name))
+		}
+		seen[name] = true
+		if h.Value != nil && strings.ContainsAny(*h.Value, "\r\n") {
+			r.Add(headerEntry(ErrInvalidHeaderValue, h.Name))
+		}
+	}
+	return r
+}
+
+func headerEntry(err error, name string) report.Entry {
+	return report.Entry{
+		Message: fmt.Sprintf("%s: %q", err.Error(), name),
+		Code:    report.CodeOf(err),
+		Kind:    report.EntryError,
+	}
+}
+
+// isToken returns whether s is a valid header name, as defined by RFC 7230.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c > 0x7f || !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return true
+}
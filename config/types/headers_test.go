@@ -0,0 +1,68 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHTTPHeadersValidate(t *testing.T) {
+	strToPtr := func(p string) *string { return &p }
+
+	tests := []struct {
+		in    []HTTPHeader
+		codes []string
+	}{
+		{
+			in:    nil,
+			codes: []string{},
+		},
+		{
+			in: []HTTPHeader{
+				{Name: "Authorization", Value: strToPtr("Bearer abc")},
+				{Name: "X-Custom", Value: strToPtr("")},
+				{Name: "User-Agent"},
+			},
+			codes: []string{},
+		},
+		{
+			in:    []HTTPHeader{{Name: ""}, {Name: "Bad Name"}, {Name: "Bad:Name"}},
+			codes: []string{"IGN-COMMON-008", "IGN-COMMON-008", "IGN-COMMON-008"},
+		},
+		{
+			in:    []HTTPHeader{{Name: "host", Value: strToPtr("example.com")}, {Name: "Transfer-Encoding"}},
+			codes: []string{"IGN-COMMON-009", "IGN-COMMON-009"},
+		},
+		{
+			in:    []HTTPHeader{{Name: "X-Custom", Value: strToPtr("a")}, {Name: "x-custom", Value: strToPtr("b")}},
+			codes: []string{"IGN-COMMON-010"},
+		},
+		{
+			in:    []HTTPHeader{{Name: "X-Custom", Value: strToPtr("a\r\nHost: example.com")}},
+			codes: []string{"IGN-COMMON-011"},
+		},
+	}
+
+	for i, test := range tests {
+		codes := []string{}
+		for _, e := range validateHTTPHeaders(test.in).Entries {
+			codes = append(codes, e.Code)
+		}
+		if !reflect.DeepEqual(test.codes, codes) {
+			t.Errorf("#%d: bad codes: want %v, got %v", i, test.codes, codes)
+		}
+	}
+}
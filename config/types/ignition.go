@@ -15,17 +15,16 @@
 package types
 
 import (
-	"errors"
-
 	"github.com/coreos/go-semver/semver"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrOldVersion     = errors.New("incorrect config version (too old)")
-	ErrNewVersion     = errors.New("incorrect config version (too new)")
-	ErrInvalidVersion = errors.New("invalid config version (couldn't parse)")
+	ErrOldVersion     = report.NewError("IGN-IGNITION-001", "incorrect config version (too old)")
+	ErrNewVersion     = report.NewError("IGN-IGNITION-002", "incorrect config version (too new)")
+	ErrInvalidVersion = report.NewError("IGN-IGNITION-003", "invalid config version (couldn't parse)")
+	ErrMirrorsNoHash  = report.NewError("IGN-IGNITION-004", "config mirrors require a verification hash")
 )
 
 func (c ConfigReference) ValidateSource() report.Report {
@@ -34,12 +33,37 @@ func (c ConfigReference) ValidateSource() report.Report {
 	if err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    urlErrorCode(err),
 			Kind:    report.EntryError,
 		})
 	}
 	return r
 }
 
+func (c ConfigReference) ValidateMirrors() report.Report {
+	r := report.Report{}
+	if len(c.Mirrors) == 0 {
+		return r
+	}
+	if c.Verification.Hash == nil {
+		r.Add(report.Entry{
+			Message: ErrMirrorsNoHash.Error(),
+			Code:    report.CodeOf(ErrMirrorsNoHash),
+			Kind:    report.EntryError,
+		})
+	}
+	for _, m := range c.Mirrors {
+		if err := validateURL(string(m)); err != nil {
+			r.Add(report.Entry{
+				Message: err.Error(),
+				Code:    urlErrorCode(err),
+				Kind:    report.EntryError,
+			})
+		}
+	}
+	return r
+}
+
 func (v Ignition) Semver() (*semver.Version, error) {
 	return semver.NewVersion(v.Version)
 }
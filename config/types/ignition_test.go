@@ -0,0 +1,73 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestConfigReferenceValidateMirrors(t *testing.T) {
+	type in struct {
+		ref ConfigReference
+	}
+	type out struct {
+		r report.Report
+	}
+
+	hash := "sha512-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{ref: ConfigReference{Source: "http://a/config.ign"}},
+			out: out{},
+		},
+		{
+			in: in{ref: ConfigReference{
+				Source:       "http://a/config.ign",
+				Mirrors:      []ConfigReferenceMirror{"http://b/config.ign", "s3://c/config.ign"},
+				Verification: Verification{Hash: &hash},
+			}},
+			out: out{},
+		},
+		{
+			in: in{ref: ConfigReference{
+				Source:  "http://a/config.ign",
+				Mirrors: []ConfigReferenceMirror{"http://b/config.ign"},
+			}},
+			out: out{r: report.ReportFromError(ErrMirrorsNoHash, report.EntryError)},
+		},
+		{
+			in: in{ref: ConfigReference{
+				Source:       "http://a/config.ign",
+				Mirrors:      []ConfigReferenceMirror{"foo://b/config.ign"},
+				Verification: Verification{Hash: &hash},
+			}},
+			out: out{r: report.ReportFromError(ErrInvalidScheme, report.EntryError)},
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.ref.ValidateMirrors()
+		if !reflect.DeepEqual(test.out.r, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out.r, r)
+		}
+	}
+}
@@ -27,6 +27,7 @@ func (s Link) Validate() report.Report {
 		if err != nil {
 			r.Add(report.Entry{
 				Message: fmt.Sprintf("problem with target path %q: %v", s.Target, err),
+				Code:    report.CodeOf(err),
 				Kind:    report.EntryError,
 			})
 		}
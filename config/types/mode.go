@@ -15,11 +15,11 @@
 package types
 
 import (
-	"errors"
+	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrFileIllegalMode = errors.New("illegal file mode")
+	ErrFileIllegalMode = report.NewError("IGN-COMMON-002", "illegal file mode")
 )
 
 func validateMode(m *int) error {
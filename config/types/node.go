@@ -15,15 +15,14 @@
 package types
 
 import (
-	"errors"
 	"path/filepath"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrNoFilesystem     = errors.New("no filesystem specified")
-	ErrBothIDAndNameSet = errors.New("cannot set both id and name")
+	ErrNoFilesystem     = report.NewError("IGN-STORAGE-022", "no filesystem specified")
+	ErrBothIDAndNameSet = report.NewError("IGN-STORAGE-023", "cannot set both id and name")
 )
 
 func (n Node) ValidateFilesystem() report.Report {
@@ -31,6 +30,7 @@ func (n Node) ValidateFilesystem() report.Report {
 	if n.Filesystem == "" {
 		r.Add(report.Entry{
 			Message: ErrNoFilesystem.Error(),
+			Code:    report.CodeOf(ErrNoFilesystem),
 			Kind:    report.EntryError,
 		})
 	}
@@ -42,6 +42,7 @@ func (n Node) ValidatePath() report.Report {
 	if err := validatePath(n.Path); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(err),
 			Kind:    report.EntryError,
 		})
 	}
@@ -61,6 +62,7 @@ func (nu NodeUser) Validate() report.Report {
 	if nu.ID != nil && nu.Name != "" {
 		r.Add(report.Entry{
 			Message: ErrBothIDAndNameSet.Error(),
+			Code:    report.CodeOf(ErrBothIDAndNameSet),
 			Kind:    report.EntryError,
 		})
 	}
@@ -71,6 +73,7 @@ func (ng NodeGroup) Validate() report.Report {
 	if ng.ID != nil && ng.Name != "" {
 		r.Add(report.Entry{
 			Message: ErrBothIDAndNameSet.Error(),
+			Code:    report.CodeOf(ErrBothIDAndNameSet),
 			Kind:    report.EntryError,
 		})
 	}
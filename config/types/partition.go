@@ -15,7 +15,6 @@
 package types
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -28,9 +27,9 @@ const (
 )
 
 var (
-	ErrLabelTooLong         = errors.New("partition labels may not exceed 36 characters")
-	ErrDoesntMatchGUIDRegex = errors.New("doesn't match the form \"01234567-89AB-CDEF-EDCB-A98765432101\"")
-	ErrLabelContainsColon   = errors.New("partition label will be truncated to text before the colon")
+	ErrLabelTooLong         = report.NewError("IGN-STORAGE-012", "partition labels may not exceed 36 characters")
+	ErrDoesntMatchGUIDRegex = report.NewError("IGN-STORAGE-013", "doesn't match the form \"01234567-89AB-CDEF-EDCB-A98765432101\"")
+	ErrLabelContainsColon   = report.NewError("IGN-STORAGE-014", "partition label will be truncated to text before the colon")
 )
 
 func (p Partition) ValidateLabel() report.Report {
@@ -43,6 +42,7 @@ func (p Partition) ValidateLabel() report.Report {
 	if len(p.Label) > 36 {
 		r.Add(report.Entry{
 			Message: ErrLabelTooLong.Error(),
+			Code:    report.CodeOf(ErrLabelTooLong),
 			Kind:    report.EntryError,
 		})
 	}
@@ -51,6 +51,7 @@ func (p Partition) ValidateLabel() report.Report {
 	if strings.Contains(p.Label, ":") {
 		r.Add(report.Entry{
 			Message: ErrLabelContainsColon.Error(),
+			Code:    report.CodeOf(ErrLabelContainsColon),
 			Kind:    report.EntryWarning,
 		})
 	}
@@ -71,11 +72,13 @@ func validateGUID(guid string) report.Report {
 	if err != nil {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("error matching guid regexp: %v", err),
+			Code:    report.CodeOf(ErrDoesntMatchGUIDRegex),
 			Kind:    report.EntryError,
 		})
 	} else if !ok {
 		r.Add(report.Entry{
 			Message: ErrDoesntMatchGUIDRegex.Error(),
+			Code:    report.CodeOf(ErrDoesntMatchGUIDRegex),
 			Kind:    report.EntryError,
 		})
 	}
@@ -15,23 +15,21 @@
 package types
 
 import (
-	"errors"
-
 	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrPasswdCreateDeprecated      = errors.New("the create object has been deprecated in favor of user-level options")
-	ErrPasswdCreateAndGecos        = errors.New("cannot use both the create object and the user-level gecos field")
-	ErrPasswdCreateAndGroups       = errors.New("cannot use both the create object and the user-level groups field")
-	ErrPasswdCreateAndHomeDir      = errors.New("cannot use both the create object and the user-level homeDir field")
-	ErrPasswdCreateAndNoCreateHome = errors.New("cannot use both the create object and the user-level noCreateHome field")
-	ErrPasswdCreateAndNoLogInit    = errors.New("cannot use both the create object and the user-level noLogInit field")
-	ErrPasswdCreateAndNoUserGroup  = errors.New("cannot use both the create object and the user-level noUserGroup field")
-	ErrPasswdCreateAndPrimaryGroup = errors.New("cannot use both the create object and the user-level primaryGroup field")
-	ErrPasswdCreateAndShell        = errors.New("cannot use both the create object and the user-level shell field")
-	ErrPasswdCreateAndSystem       = errors.New("cannot use both the create object and the user-level system field")
-	ErrPasswdCreateAndUID          = errors.New("cannot use both the create object and the user-level uid field")
+	ErrPasswdCreateDeprecated      = report.NewError("IGN-PASSWD-001", "the create object has been deprecated in favor of user-level options")
+	ErrPasswdCreateAndGecos        = report.NewError("IGN-PASSWD-002", "cannot use both the create object and the user-level gecos field")
+	ErrPasswdCreateAndGroups       = report.NewError("IGN-PASSWD-003", "cannot use both the create object and the user-level groups field")
+	ErrPasswdCreateAndHomeDir      = report.NewError("IGN-PASSWD-004", "cannot use both the create object and the user-level homeDir field")
+	ErrPasswdCreateAndNoCreateHome = report.NewError("IGN-PASSWD-005", "cannot use both the create object and the user-level noCreateHome field")
+	ErrPasswdCreateAndNoLogInit    = report.NewError("IGN-PASSWD-006", "cannot use both the create object and the user-level noLogInit field")
+	ErrPasswdCreateAndNoUserGroup  = report.NewError("IGN-PASSWD-007", "cannot use both the create object and the user-level noUserGroup field")
+	ErrPasswdCreateAndPrimaryGroup = report.NewError("IGN-PASSWD-008", "cannot use both the create object and the user-level primaryGroup field")
+	ErrPasswdCreateAndShell        = report.NewError("IGN-PASSWD-009", "cannot use both the create object and the user-level shell field")
+	ErrPasswdCreateAndSystem       = report.NewError("IGN-PASSWD-010", "cannot use both the create object and the user-level system field")
+	ErrPasswdCreateAndUID          = report.NewError("IGN-PASSWD-011", "cannot use both the create object and the user-level uid field")
 )
 
 func (p PasswdUser) Validate() report.Report {
@@ -39,11 +37,13 @@ func (p PasswdUser) Validate() report.Report {
 	if p.Create != nil {
 		r.Add(report.Entry{
 			Message: ErrPasswdCreateDeprecated.Error(),
+			Code:    report.CodeOf(ErrPasswdCreateDeprecated),
 			Kind:    report.EntryWarning,
 		})
 		addErr := func(err error) {
 			r.Add(report.Entry{
 				Message: err.Error(),
+				Code:    report.CodeOf(err),
 				Kind:    report.EntryError,
 			})
 		}
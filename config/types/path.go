@@ -15,12 +15,13 @@
 package types
 
 import (
-	"errors"
 	"path"
+
+	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrPathRelative = errors.New("path not absolute")
+	ErrPathRelative = report.NewError("IGN-COMMON-001", "path not absolute")
 )
 
 func validatePath(p string) error {
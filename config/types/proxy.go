@@ -0,0 +1,66 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+var (
+	ErrInvalidProxy        = report.NewError("IGN-IGNITION-005", "invalid proxy url, must be an http or https url")
+	ErrInvalidNoProxyEntry = report.NewError("IGN-IGNITION-006", "invalid noProxy entry")
+)
+
+func (p Proxy) ValidateHTTPProxy() report.Report {
+	return validateProxyURL(p.HTTPProxy)
+}
+
+func (p Proxy) ValidateHTTPSProxy() report.Report {
+	return validateProxyURL(p.HTTPSProxy)
+}
+
+func (p Proxy) ValidateNoProxy() report.Report {
+	r := report.Report{}
+	for _, n := range p.NoProxy {
+		if n == "" || strings.ContainsAny(string(n), ", \t\n") {
+			r.Add(report.Entry{
+				Message: fmt.Sprintf("%s %q", ErrInvalidNoProxyEntry.Error(), n),
+				Code:    report.CodeOf(ErrInvalidNoProxyEntry),
+				Kind:    report.EntryError,
+			})
+		}
+	}
+	return r
+}
+
+func validateProxyURL(s *string) report.Report {
+	r := report.Report{}
+	if s == nil {
+		return r
+	}
+	u, err := url.Parse(*s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		r.Add(report.Entry{
+			Message: fmt.Sprintf("%s: %q", ErrInvalidProxy.Error(), *s),
+			Code:    report.CodeOf(ErrInvalidProxy),
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
@@ -20,6 +20,11 @@ import (
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+var (
+	ErrSparesUnsupportedForLevel = report.NewError("IGN-STORAGE-028", "spares unsupported for raid level")
+	ErrUnrecognizedRaidLevel     = report.NewError("IGN-STORAGE-029", "unrecognized raid level")
+)
+
 func (n Raid) ValidateLevel() report.Report {
 	r := report.Report{}
 	switch n.Level {
@@ -27,6 +32,7 @@ func (n Raid) ValidateLevel() report.Report {
 		if n.Spares != 0 {
 			r.Add(report.Entry{
 				Message: fmt.Sprintf("spares unsupported for %q arrays", n.Level),
+				Code:    report.CodeOf(ErrSparesUnsupportedForLevel),
 				Kind:    report.EntryError,
 			})
 		}
@@ -37,7 +43,8 @@ func (n Raid) ValidateLevel() report.Report {
 	case "raid10", "10":
 	default:
 		r.Add(report.Entry{
-			Message: fmt.Sprintf("unrecognized raid level: %q", n.Level),
+			Message: fmt.Sprintf("%v: %q", ErrUnrecognizedRaidLevel, n.Level),
+			Code:    report.CodeOf(ErrUnrecognizedRaidLevel),
 			Kind:    report.EntryError,
 		})
 	}
@@ -50,6 +57,7 @@ func (n Raid) ValidateDevices() report.Report {
 		if err := validatePath(string(d)); err != nil {
 			r.Add(report.Entry{
 				Message: fmt.Sprintf("array %q: device path not absolute: %q", n.Name, d),
+				Code:    report.CodeOf(err),
 				Kind:    report.EntryError,
 			})
 		}
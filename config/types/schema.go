@@ -3,6 +3,7 @@ package types
 // generated by "schematyper --package=types schema/ignition.json -o config/types/schema.go --root-type=Config" -- DO NOT EDIT
 
 type CaReference struct {
+	HTTPHeaders  []HTTPHeader `json:"httpHeaders,omitempty"`
 	Source       string       `json:"source,omitempty"`
 	Verification Verification `json:"verification,omitempty"`
 }
@@ -16,10 +17,14 @@ type Config struct {
 }
 
 type ConfigReference struct {
-	Source       string       `json:"source,omitempty"`
-	Verification Verification `json:"verification,omitempty"`
+	HTTPHeaders  []HTTPHeader            `json:"httpHeaders,omitempty"`
+	Mirrors      []ConfigReferenceMirror `json:"mirrors,omitempty"`
+	Source       string                  `json:"source,omitempty"`
+	Verification Verification            `json:"verification,omitempty"`
 }
 
+type ConfigReferenceMirror string
+
 type Create struct {
 	Force   bool           `json:"force,omitempty"`
 	Options []CreateOption `json:"options,omitempty"`
@@ -51,6 +56,7 @@ type File struct {
 
 type FileContents struct {
 	Compression  string       `json:"compression,omitempty"`
+	HTTPHeaders  []HTTPHeader `json:"httpHeaders,omitempty"`
 	Source       string       `json:"source,omitempty"`
 	Verification Verification `json:"verification,omitempty"`
 }
@@ -69,8 +75,14 @@ type Filesystem struct {
 
 type Group string
 
+type HTTPHeader struct {
+	Name  string  `json:"name,omitempty"`
+	Value *string `json:"value,omitempty"`
+}
+
 type Ignition struct {
 	Config   IgnitionConfig `json:"config,omitempty"`
+	Proxy    Proxy          `json:"proxy,omitempty"`
 	Security Security       `json:"security,omitempty"`
 	Timeouts Timeouts       `json:"timeouts,omitempty"`
 	Version  string         `json:"version,omitempty"`
@@ -92,15 +104,19 @@ type LinkEmbedded1 struct {
 }
 
 type Mount struct {
-	Create         *Create       `json:"create,omitempty"`
-	Device         string        `json:"device,omitempty"`
-	Format         string        `json:"format,omitempty"`
-	Label          *string       `json:"label,omitempty"`
-	Options        []MountOption `json:"options,omitempty"`
-	UUID           *string       `json:"uuid,omitempty"`
-	WipeFilesystem bool          `json:"wipeFilesystem,omitempty"`
+	Create         *Create            `json:"create,omitempty"`
+	Device         string             `json:"device,omitempty"`
+	Format         string             `json:"format,omitempty"`
+	Label          *string            `json:"label,omitempty"`
+	MountOptions   []MountMountOption `json:"mountOptions,omitempty"`
+	MountPath      *string            `json:"mountPath,omitempty"`
+	Options        []MountOption      `json:"options,omitempty"`
+	UUID           *string            `json:"uuid,omitempty"`
+	WipeFilesystem bool               `json:"wipeFilesystem,omitempty"`
 }
 
+type MountMountOption string
+
 type MountOption string
 
 type Networkd struct {
@@ -118,6 +134,8 @@ type Networkdunit struct {
 	Name     string           `json:"name,omitempty"`
 }
 
+type NoProxyItem string
+
 type Node struct {
 	Filesystem string     `json:"filesystem,omitempty"`
 	Group      *NodeGroup `json:"group,omitempty"`
@@ -174,6 +192,12 @@ type PasswdUser struct {
 	UID               *int               `json:"uid,omitempty"`
 }
 
+type Proxy struct {
+	HTTPProxy  *string       `json:"httpProxy,omitempty"`
+	HTTPSProxy *string       `json:"httpsProxy,omitempty"`
+	NoProxy    []NoProxyItem `json:"noProxy,omitempty"`
+}
+
 type Raid struct {
 	Devices []Device     `json:"devices,omitempty"`
 	Level   string       `json:"level,omitempty"`
@@ -242,5 +266,6 @@ type Usercreate struct {
 type UsercreateGroup string
 
 type Verification struct {
-	Hash *string `json:"hash,omitempty"`
+	Hash      *string `json:"hash,omitempty"`
+	Signature *string `json:"signature,omitempty"`
 }
@@ -16,7 +16,6 @@ package types
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"path"
 
@@ -26,8 +25,11 @@ import (
 )
 
 var (
-	ErrInvalidSystemdExt  = errors.New("invalid systemd unit extension")
-	ErrInvalidNetworkdExt = errors.New("invalid networkd unit extension")
+	ErrInvalidSystemdExt        = report.NewError("IGN-SYSTEMD-001", "invalid systemd unit extension")
+	ErrInvalidSystemdDropinExt  = report.NewError("IGN-SYSTEMD-002", "invalid systemd unit drop-in extension")
+	ErrInvalidUnitContent       = report.NewError("IGN-SYSTEMD-003", "invalid unit content")
+	ErrInvalidNetworkdExt       = report.NewError("IGN-NETWORKD-001", "invalid networkd unit extension")
+	ErrInvalidNetworkdDropinExt = report.NewError("IGN-NETWORKD-002", "invalid networkd unit drop-in extension")
 )
 
 func (u Unit) ValidateContents() report.Report {
@@ -35,6 +37,7 @@ func (u Unit) ValidateContents() report.Report {
 	if err := validateUnitContent(u.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(ErrInvalidUnitContent),
 			Kind:    report.EntryError,
 		})
 	}
@@ -48,6 +51,7 @@ func (u Unit) ValidateName() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: ErrInvalidSystemdExt.Error(),
+			Code:    report.CodeOf(ErrInvalidSystemdExt),
 			Kind:    report.EntryError,
 		})
 	}
@@ -60,6 +64,7 @@ func (d SystemdDropin) Validate() report.Report {
 	if err := validateUnitContent(d.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(ErrInvalidUnitContent),
 			Kind:    report.EntryError,
 		})
 	}
@@ -68,7 +73,8 @@ func (d SystemdDropin) Validate() report.Report {
 	case ".conf":
 	default:
 		r.Add(report.Entry{
-			Message: fmt.Sprintf("invalid systemd unit drop-in extension: %q", path.Ext(d.Name)),
+			Message: fmt.Sprintf("%v: %q", ErrInvalidSystemdDropinExt, path.Ext(d.Name)),
+			Code:    report.CodeOf(ErrInvalidSystemdDropinExt),
 			Kind:    report.EntryError,
 		})
 	}
@@ -82,6 +88,7 @@ func (u Networkdunit) Validate() report.Report {
 	if err := validateUnitContent(u.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(ErrInvalidUnitContent),
 			Kind:    report.EntryError,
 		})
 	}
@@ -91,6 +98,7 @@ func (u Networkdunit) Validate() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: ErrInvalidNetworkdExt.Error(),
+			Code:    report.CodeOf(ErrInvalidNetworkdExt),
 			Kind:    report.EntryError,
 		})
 	}
@@ -104,6 +112,7 @@ func (d NetworkdDropin) Validate() report.Report {
 	if err := validateUnitContent(d.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(ErrInvalidUnitContent),
 			Kind:    report.EntryError,
 		})
 	}
@@ -112,7 +121,8 @@ func (d NetworkdDropin) Validate() report.Report {
 	case ".conf":
 	default:
 		r.Add(report.Entry{
-			Message: fmt.Sprintf("invalid networkd unit drop-in extension: %q", path.Ext(d.Name)),
+			Message: fmt.Sprintf("%v: %q", ErrInvalidNetworkdDropinExt, path.Ext(d.Name)),
+			Code:    report.CodeOf(ErrInvalidNetworkdDropinExt),
 			Kind:    report.EntryError,
 		})
 	}
@@ -124,7 +134,7 @@ func validateUnitContent(content string) error {
 	c := bytes.NewBufferString(content)
 	_, err := unit.Deserialize(c)
 	if err != nil {
-		return fmt.Errorf("invalid unit content: %s", err)
+		return fmt.Errorf("%v: %s", ErrInvalidUnitContent, err)
 	}
 
 	return nil
@@ -15,7 +15,6 @@
 package types
 
 import (
-	"errors"
 	"reflect"
 	"testing"
 
@@ -39,8 +38,11 @@ func TestSystemdUnitValidateContents(t *testing.T) {
 			out: out{err: nil},
 		},
 		{
-			in:  in{unit: Unit{Name: "test.service", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			in: in{unit: Unit{Name: "test.service", Contents: "[Foo"}},
+			out: out{err: &report.Error{
+				Code:    report.CodeOf(ErrInvalidUnitContent),
+				Message: "invalid unit content: unable to find end of section",
+			}},
 		},
 		{
 			in:  in{unit: Unit{Name: "test.service", Contents: "", Dropins: []SystemdDropin{{}}}},
@@ -107,8 +109,11 @@ func TestSystemdUnitDropInValidate(t *testing.T) {
 			out: out{err: nil},
 		},
 		{
-			in:  in{unit: SystemdDropin{Name: "test.conf", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			in: in{unit: SystemdDropin{Name: "test.conf", Contents: "[Foo"}},
+			out: out{err: &report.Error{
+				Code:    report.CodeOf(ErrInvalidUnitContent),
+				Message: "invalid unit content: unable to find end of section",
+			}},
 		},
 	}
 
@@ -175,8 +180,11 @@ func TestNetworkdUnitValidate(t *testing.T) {
 			out: out{err: nil},
 		},
 		{
-			in:  in{unit: Networkdunit{Name: "test.network", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			in: in{unit: Networkdunit{Name: "test.network", Contents: "[Foo"}},
+			out: out{err: &report.Error{
+				Code:    report.CodeOf(ErrInvalidUnitContent),
+				Message: "invalid unit content: unable to find end of section",
+			}},
 		},
 	}
 
@@ -205,8 +213,11 @@ func TestNetworkdUnitDropInValidate(t *testing.T) {
 			out: out{err: nil},
 		},
 		{
-			in:  in{unit: NetworkdDropin{Name: "test.conf", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			in: in{unit: NetworkdDropin{Name: "test.conf", Contents: "[Foo"}},
+			out: out{err: &report.Error{
+				Code:    report.CodeOf(ErrInvalidUnitContent),
+				Message: "invalid unit content: unable to find end of section",
+			}},
 		},
 	}
 
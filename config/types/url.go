@@ -15,16 +15,27 @@
 package types
 
 import (
-	"errors"
 	"net/url"
 
 	"github.com/vincent-petithory/dataurl"
+
+	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrInvalidScheme = errors.New("invalid url scheme")
+	ErrInvalidScheme = report.NewError("IGN-COMMON-003", "invalid url scheme")
+	ErrInvalidURL    = report.NewError("IGN-COMMON-004", "invalid url")
 )
 
+// urlErrorCode returns the code of an error returned by validateURL. Errors
+// from parsing the url have no code of their own.
+func urlErrorCode(err error) string {
+	if code := report.CodeOf(err); code != "" {
+		return code
+	}
+	return report.CodeOf(ErrInvalidURL)
+}
+
 func validateURL(s string) error {
 	// Empty url is valid, indicates an empty file
 	if s == "" {
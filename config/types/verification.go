@@ -17,16 +17,16 @@ package types
 import (
 	"crypto"
 	"encoding/hex"
-	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
 var (
-	ErrHashMalformed    = errors.New("malformed hash specifier")
-	ErrHashWrongSize    = errors.New("incorrect size for hash sum")
-	ErrHashUnrecognized = errors.New("unrecognized hash function")
+	ErrHashMalformed    = report.NewError("IGN-COMMON-005", "malformed hash specifier")
+	ErrHashWrongSize    = report.NewError("IGN-COMMON-006", "incorrect size for hash sum")
+	ErrHashUnrecognized = report.NewError("IGN-COMMON-007", "unrecognized hash function")
 )
 
 // HashParts will return the sum and function (in that order) of the hash stored
@@ -56,17 +56,23 @@ func (v Verification) Validate() report.Report {
 	if err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    report.CodeOf(err),
 			Kind:    report.EntryError,
 		})
 		return r
 	}
 	var hash crypto.Hash
 	switch function {
+	case "sha256":
+		hash = crypto.SHA256
+	case "sha384":
+		hash = crypto.SHA384
 	case "sha512":
 		hash = crypto.SHA512
 	default:
 		r.Add(report.Entry{
 			Message: ErrHashUnrecognized.Error(),
+			Code:    report.CodeOf(ErrHashUnrecognized),
 			Kind:    report.EntryError,
 		})
 		return r
@@ -75,9 +81,26 @@ func (v Verification) Validate() report.Report {
 	if len(sum) != hex.EncodedLen(hash.Size()) {
 		r.Add(report.Entry{
 			Message: ErrHashWrongSize.Error(),
+			Code:    report.CodeOf(ErrHashWrongSize),
 			Kind:    report.EntryError,
 		})
 	}
 
 	return r
 }
+
+// ValidateSignature checks the url of the detached signature, if there is one.
+func (v Verification) ValidateSignature() report.Report {
+	r := report.Report{}
+	if v.Signature == nil {
+		return r
+	}
+	if err := validateURL(*v.Signature); err != nil {
+		r.Add(report.Entry{
+			Message: fmt.Sprintf("invalid signature url %q: %v", *v.Signature, err),
+			Code:    urlErrorCode(err),
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
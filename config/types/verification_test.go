@@ -64,6 +64,9 @@ func TestHashValidate(t *testing.T) {
 	h1 := "xor-abcdef"
 	h2 := "sha512-123"
 	h3 := "sha512-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	h4 := "sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	h5 := "sha384-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	h6 := "sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 
 	tests := []struct {
 		in  in
@@ -81,6 +84,18 @@ func TestHashValidate(t *testing.T) {
 			in:  in{v: Verification{Hash: &h3}},
 			out: out{},
 		},
+		{
+			in:  in{v: Verification{Hash: &h4}},
+			out: out{},
+		},
+		{
+			in:  in{v: Verification{Hash: &h5}},
+			out: out{},
+		},
+		{
+			in:  in{v: Verification{Hash: &h6}},
+			out: out{err: ErrHashWrongSize},
+		},
 	}
 
 	for i, test := range tests {
@@ -90,3 +105,29 @@ func TestHashValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestSignatureValidate(t *testing.T) {
+	strToPtr := func(p string) *string { return &p }
+
+	tests := []struct {
+		in   *string
+		code string
+	}{
+		{nil, ""},
+		{strToPtr("https://example.com/config.ign.sig"), ""},
+		{strToPtr("data:;base64,MEUCIQ=="), ""},
+		{strToPtr("ftp://example.com/config.ign.sig"), "IGN-COMMON-003"},
+		{strToPtr("data:;base64,!!"), "IGN-COMMON-004"},
+	}
+
+	for i, test := range tests {
+		r := Verification{Signature: test.in}.ValidateSignature()
+		code := ""
+		if len(r.Entries) > 0 {
+			code = r.Entries[0].Code
+		}
+		if code != test.code {
+			t.Errorf("#%d: bad code: want %q, got %q (%v)", i, test.code, code, r)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Error is an error identified by a stable code. Codes are never changed or
+// reused once assigned, so tooling can match on them rather than on messages,
+// whose wording may change.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var catalogue = map[string]*Error{}
+
+// NewError returns an Error with the given code and message and adds it to the
+// catalogue of codes. It panics if the code is already in use.
+func NewError(code, message string) error {
+	if _, ok := catalogue[code]; ok {
+		panic(fmt.Sprintf("report: code %s registered twice", code))
+	}
+	e := &Error{Code: code, Message: message}
+	catalogue[code] = e
+	return e
+}
+
+// CodeOf returns the code of err, or "" if err is not an Error.
+func CodeOf(err error) string {
+	if e, ok := err.(*Error); ok {
+		return e.Code
+	}
+	return ""
+}
+
+// Codes returns the catalogue of all registered errors, sorted by code.
+func Codes() []Error {
+	codes := []Error{}
+	for _, e := range catalogue {
+		codes = append(codes, *e)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// AddCodes sets the code of all the entries without one whose message is that
+// of a registered error. This assigns codes to the findings of older spec
+// versions, which share the messages of the latest spec.
+func (r *Report) AddCodes() {
+	for i, e := range r.Entries {
+		if e.Code != "" {
+			continue
+		}
+		for _, c := range Codes() {
+			if c.Message == e.Message {
+				r.Entries[i].Code = c.Code
+				break
+			}
+		}
+	}
+}
+
+// Suppress removes all the entries which aren't errors and have one of the
+// given codes. Errors cannot be suppressed, since they make the config invalid.
+func (r *Report) Suppress(codes []string) {
+	suppressed := map[string]struct{}{}
+	for _, c := range codes {
+		suppressed[c] = struct{}{}
+	}
+	entries := []Entry{}
+	for _, e := range r.Entries {
+		if _, ok := suppressed[e.Code]; ok && e.Kind != EntryError {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	r.Entries = entries
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var errTest = NewError("IGN-TEST-001", "test error")
+
+func TestCodeOf(t *testing.T) {
+	if code := CodeOf(errTest); code != "IGN-TEST-001" {
+		t.Errorf("bad code: want IGN-TEST-001, got %q", code)
+	}
+	if code := CodeOf(errors.New("test error")); code != "" {
+		t.Errorf("bad code for plain error: got %q", code)
+	}
+	if code := ReportFromError(errTest, EntryError).Entries[0].Code; code != "IGN-TEST-001" {
+		t.Errorf("bad code from ReportFromError: got %q", code)
+	}
+}
+
+func TestAddCodes(t *testing.T) {
+	r := Report{Entries: []Entry{
+		{Kind: EntryError, Message: "test error"},
+		{Kind: EntryError, Message: "other error"},
+	}}
+	r.AddCodes()
+	expected := Report{Entries: []Entry{
+		{Kind: EntryError, Message: "test error", Code: "IGN-TEST-001"},
+		{Kind: EntryError, Message: "other error"},
+	}}
+	if !reflect.DeepEqual(expected, r) {
+		t.Errorf("bad report: want %v, got %v", expected, r)
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	r := Report{Entries: []Entry{
+		{Kind: EntryWarning, Message: "a", Code: "IGN-TEST-001"},
+		{Kind: EntryError, Message: "b", Code: "IGN-TEST-001"},
+		{Kind: EntryWarning, Message: "c", Code: "IGN-TEST-002"},
+		{Kind: EntryInfo, Message: "d"},
+	}}
+	r.Suppress([]string{"IGN-TEST-001"})
+	expected := Report{Entries: []Entry{
+		{Kind: EntryError, Message: "b", Code: "IGN-TEST-001"},
+		{Kind: EntryWarning, Message: "c", Code: "IGN-TEST-002"},
+		{Kind: EntryInfo, Message: "d"},
+	}}
+	if !reflect.DeepEqual(expected, r) {
+		t.Errorf("bad report: want %v, got %v", expected, r)
+	}
+}
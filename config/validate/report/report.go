@@ -38,6 +38,7 @@ func ReportFromError(err error, severity entryKind) Report {
 			{
 				Kind:    severity,
 				Message: err.Error(),
+				Code:    CodeOf(err),
 			},
 		},
 	}
@@ -85,6 +86,20 @@ func (r *Report) AddPosition(line, col int, highlight string) {
 	}
 }
 
+// AddPath prefixes the path of all the entries with name, the key or index of
+// the field they were found in. Entries without a path are given name as their
+// path. This is used while walking down a config to build the logical path of
+// each entry, such as "storage.files.3.contents.source".
+func (r *Report) AddPath(name string) {
+	for i, e := range r.Entries {
+		if e.Path == "" {
+			r.Entries[i].Path = name
+		} else {
+			r.Entries[i].Path = name + "." + e.Path
+		}
+	}
+}
+
 func (r *Report) Add(e Entry) {
 	r.Entries = append(r.Entries, e)
 }
@@ -124,16 +139,27 @@ func (r Report) IsDeprecated() bool {
 type Entry struct {
 	Kind      entryKind `json:"kind"`
 	Message   string    `json:"message"`
+	Code      string    `json:"code,omitempty"`
+	Path      string    `json:"path,omitempty"`
 	Line      int       `json:"line,omitempty"`
 	Column    int       `json:"column,omitempty"`
-	Highlight string    `json:"-"`
+	Highlight string    `json:"highlight,omitempty"`
 }
 
 func (e Entry) String() string {
-	if e.Line != 0 {
-		return fmt.Sprintf("%s at line %d, column %d\n%s%v", e.Kind.String(), e.Line, e.Column, e.Highlight, e.Message)
+	kind := e.Kind.String()
+	if e.Code != "" {
+		kind = fmt.Sprintf("%s[%s]", kind, e.Code)
+	}
+	switch {
+	case e.Line != 0 && e.Path != "":
+		return fmt.Sprintf("%s at %s, line %d, column %d\n%s%v", kind, e.Path, e.Line, e.Column, e.Highlight, e.Message)
+	case e.Line != 0:
+		return fmt.Sprintf("%s at line %d, column %d\n%s%v", kind, e.Line, e.Column, e.Highlight, e.Message)
+	case e.Path != "":
+		return fmt.Sprintf("%s at %s: %v", kind, e.Path, e.Message)
 	}
-	return fmt.Sprintf("%s: %v", e.Kind.String(), e.Message)
+	return fmt.Sprintf("%s: %v", kind, e.Message)
 }
 
 type entryKind int
@@ -18,12 +18,18 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/coreos/ignition/config/validate/astnode"
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+var (
+	ErrUnrecognizedKey = report.NewError("IGN-CONFIG-011", "config has unrecognized key")
+	ErrSimilarKey      = report.NewError("IGN-CONFIG-012", "unrecognized key is similar to a known key")
+)
+
 type validator interface {
 	Validate() report.Report
 }
@@ -78,6 +84,7 @@ func Validate(vObj reflect.Value, ast astnode.AstNode, source io.ReadSeeker, che
 			}
 			sub_report := Validate(vObj.Index(i), sub_node, source, checkUnusedKeys)
 			sub_report.AddPosition(line, col, "")
+			sub_report.AddPath(strconv.Itoa(i))
 			r.Merge(sub_report)
 		}
 	}
@@ -135,9 +142,18 @@ func validateStruct(vObj reflect.Value, ast astnode.AstNode, source io.ReadSeeke
 		// This ensures the line numbers reported from all sub-structs are 0 and will be changed by AddPosition
 		var src io.ReadSeeker
 
+		// The tag names the field in the config, and so in the path of any entries found in it
+		tagName := "json"
+		if ast != nil {
+			tagName = ast.Tag()
+		}
+		tag := strings.SplitN(f.Type.Tag.Get(tagName), ",", 2)[0]
+		if tag == "" {
+			tag = f.Type.Name
+		}
+
 		// Try to determine the json.Node that corrosponds with the struct field
 		if isFromObject {
-			tag := strings.SplitN(f.Type.Tag.Get(ast.Tag()), ",", 2)[0]
 			// Save the tag so we have a list of all the tags in the struct
 			tags = append(tags, tag)
 			// mark that this key was used
@@ -167,11 +183,13 @@ func validateStruct(vObj reflect.Value, ast astnode.AstNode, source io.ReadSeeke
 			res := funct.Call(nil)
 			sub_report := res[0].Interface().(report.Report)
 			sub_report.AddPosition(line, col, "")
+			sub_report.AddPath(tag)
 			r.Merge(sub_report)
 		}
 
 		sub_report := Validate(f.Value, sub_node, src, checkUnusedKeys)
 		sub_report.AddPosition(line, col, "")
+		sub_report.AddPath(tag)
 		r.Merge(sub_report)
 	}
 	if !isFromObject || !checkUnusedKeys {
@@ -189,6 +207,8 @@ func validateStruct(vObj reflect.Value, ast astnode.AstNode, source io.ReadSeeke
 		r.Add(report.Entry{
 			Kind:      report.EntryWarning,
 			Message:   fmt.Sprintf("Config has unrecognized key: %s", k),
+			Code:      report.CodeOf(ErrUnrecognizedKey),
+			Path:      k,
 			Line:      line,
 			Column:    col,
 			Highlight: highlight,
@@ -198,6 +218,8 @@ func validateStruct(vObj reflect.Value, ast astnode.AstNode, source io.ReadSeeke
 			r.Add(report.Entry{
 				Kind:      report.EntryInfo,
 				Message:   fmt.Sprintf("Did you mean %s instead of %s", typo, k),
+				Code:      report.CodeOf(ErrSimilarKey),
+				Path:      k,
 				Line:      line,
 				Column:    col,
 				Highlight: highlight,
@@ -35,7 +35,8 @@ func TestValidate(t *testing.T) {
 		cfg Config
 	}
 	type out struct {
-		err error
+		err  error
+		path string
 	}
 
 	tests := []struct {
@@ -48,23 +49,23 @@ func TestValidate(t *testing.T) {
 		},
 		{
 			in:  in{cfg: Config{}},
-			out: out{err: ErrInvalidVersion},
+			out: out{err: ErrInvalidVersion, path: "ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "invalid.version"}}},
-			out: out{err: ErrInvalidVersion},
+			out: out{err: ErrInvalidVersion, path: "ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "2.2.0"}}},
-			out: out{err: ErrNewVersion},
+			out: out{err: ErrNewVersion, path: "ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "3.0.0"}}},
-			out: out{err: ErrNewVersion},
+			out: out{err: ErrNewVersion, path: "ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "1.0.0"}}},
-			out: out{err: ErrOldVersion},
+			out: out{err: ErrOldVersion, path: "ignition"},
 		},
 		{
 			in: in{cfg: Config{
@@ -79,7 +80,10 @@ func TestValidate(t *testing.T) {
 					},
 				},
 			}},
-			out: out{errors.New("unrecognized hash function")},
+			out: out{
+				err:  ErrHashUnrecognized,
+				path: "ignition.config.replace.verification",
+			},
 		},
 		{
 			in: in{cfg: Config{
@@ -117,13 +121,14 @@ func TestValidate(t *testing.T) {
 				Ignition: Ignition{Version: semver.Version{Major: 2}.String()},
 				Systemd:  Systemd{Units: []Unit{{Name: "foo.bar", Contents: "[Foo]\nfoo=qux"}}},
 			}},
-			out: out{err: errors.New("invalid systemd unit extension")},
+			out: out{err: ErrInvalidSystemdExt, path: "systemd.units.0.name"},
 		},
 	}
 
 	for i, test := range tests {
 		r := ValidateWithoutSource(reflect.ValueOf(test.in.cfg))
 		expectedReport := report.ReportFromError(test.out.err, report.EntryError)
+		expectedReport.AddPath(test.out.path)
 		if !reflect.DeepEqual(expectedReport, r) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, expectedReport, r)
 		}
@@ -178,6 +183,11 @@ func TestValidateLineCol(t *testing.T) {
 		r.AddPosition(line, col, "")
 		return r
 	}
+	reportFromDummyWithPath := func(line, col int, path string) report.Report {
+		r := reportFromDummyWithLineCol(line, col)
+		r.AddPath(path)
+		return r
+	}
 
 	tests := []struct {
 		in  in
@@ -211,7 +221,7 @@ func TestValidateLineCol(t *testing.T) {
 }`,
 				unmarshalInto: reflect.TypeOf(NamedValidate{}),
 			},
-			out: out{r: reportFromDummyWithLineCol(2, 15)},
+			out: out{r: reportFromDummyWithPath(2, 15, "a")},
 		},
 		{
 			in: in{
@@ -220,7 +230,7 @@ func TestValidateLineCol(t *testing.T) {
 }`,
 				unmarshalInto: reflect.TypeOf(NamedEmbedded{}),
 			},
-			out: out{r: reportFromDummyWithLineCol(2, 15)},
+			out: out{r: reportFromDummyWithPath(2, 15, "a")},
 		},
 		{
 			in: in{
@@ -229,7 +239,7 @@ func TestValidateLineCol(t *testing.T) {
 }`,
 				unmarshalInto: reflect.TypeOf(twiceNestedAndNamed{}),
 			},
-			out: out{r: reportFromDummyWithLineCol(2, 15)},
+			out: out{r: reportFromDummyWithPath(2, 15, "a")},
 		},
 	}
 
@@ -0,0 +1,161 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package engine runs Ignition stages from other programs. An Engine is built
+// from a Logger, a Fetcher, a root directory and, optionally, a ConfigSource,
+// and then runs named stages (e.g. "disks" or "files") against a types.Config.
+// Additional stages and providers can be added with RegisterStage and
+// RegisterProvider.
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+	"github.com/coreos/ignition/internal/exec"
+	_ "github.com/coreos/ignition/internal/exec/stages/disks"
+	_ "github.com/coreos/ignition/internal/exec/stages/files"
+	_ "github.com/coreos/ignition/internal/exec/stages/mount"
+	_ "github.com/coreos/ignition/internal/exec/stages/umount"
+)
+
+var (
+	ErrNoSource = errors.New("engine has no config source")
+)
+
+// ConfigSource fetches a config, using the supplied fetcher for any remote
+// resources.
+type ConfigSource func(f *Fetcher) (types.Config, report.Report, error)
+
+// Engine runs stages against a config.
+type Engine struct {
+	// Root is the directory the stages apply the config under.
+	Root string
+
+	// Logger is where the stages log. If nil, one logging to stdout is
+	// created with NewLogger.
+	Logger *Logger
+
+	// Fetcher is used to fetch the remote resources referenced by the
+	// config. If nil, one is created with NewFetcher the first time it is
+	// needed and kept for later calls.
+	Fetcher *Fetcher
+
+	// Source is where FetchConfig gets the config from.
+	Source ConfigSource
+
+	// FetchWorkers is the maximum number of remote files the files stage
+	// fetches at once. If zero, exec.DefaultFetchWorkers is used.
+	FetchWorkers int
+
+	// DevicesReady, if true, means the devices referenced by the config
+	// already exist (e.g. loop devices attached by the caller), so the
+	// disks stage doesn't wait for them to appear through systemd.
+	DevicesReady bool
+
+	// MergeConfigs, if true, causes Render to merge the configs referenced
+	// by "ignition.config.append" into the config with config.Merge rather
+	// than appending them.
+	MergeConfigs bool
+
+	// StrictConfigs, if true, causes FetchConfig and Render to reject configs
+	// containing keys which aren't part of the spec.
+	StrictConfigs bool
+}
+
+// FetchConfig fetches the config from e.Source, logging the entries of the
+// source's report.
+func (e *Engine) FetchConfig() (types.Config, error) {
+	if e.Source == nil {
+		return types.Config{}, ErrNoSource
+	}
+	cfg, r, err := e.Source(e.fetcher())
+	if err == nil && e.StrictConfigs && config.RejectUnknownKeys(&r) {
+		cfg, err = types.Config{}, config.ErrInvalid
+	}
+	exec.Engine{Logger: e.logger().l}.LogReport(r)
+	return cfg, err
+}
+
+// Run runs the stage of the given name against cfg, to which a "root"
+// filesystem at e.Root is added. e.Fetcher is first set up with the timeouts,
+// certificate authorities and proxy of cfg. Run doesn't evaluate
+// "ignition.config.replace" and "ignition.config.append"; see Render.
+func (e *Engine) Run(stage string, cfg types.Config) error {
+	f := e.fetcher()
+	if err := f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy); err != nil {
+		return fmt.Errorf("failed to configure fetcher: %v", err)
+	}
+	workers := e.FetchWorkers
+	if workers == 0 {
+		workers = exec.DefaultFetchWorkers
+	}
+	engine := exec.Engine{
+		Root:         e.Root,
+		Logger:       e.logger().l,
+		FetchWorkers: workers,
+		DevicesReady: e.DevicesReady,
+	}
+	if !engine.RunStage(stage, cfg, f.f) {
+		return fmt.Errorf("stage %q failed", stage)
+	}
+	return nil
+}
+
+// Render evaluates the "ignition.config.replace" and "ignition.config.append"
+// sections of cfg, fetching the referenced configs, and returns the resulting
+// config. e.Fetcher is set up with the timeouts and certificate authorities
+// of the config.
+func (e *Engine) Render(cfg types.Config) (types.Config, error) {
+	f := e.fetcher()
+	engine := exec.Engine{
+		Logger:        e.logger().l,
+		MergeConfigs:  e.MergeConfigs,
+		StrictConfigs: e.StrictConfigs,
+	}
+	cfg, rf, err := engine.RenderConfig(cfg, f.f)
+	f.f = rf
+	return cfg, err
+}
+
+// RunFromSource fetches the config with FetchConfig, renders it with Render
+// and runs the stage of the given name against it.
+func (e *Engine) RunFromSource(stage string) error {
+	cfg, err := e.FetchConfig()
+	if err != nil {
+		return fmt.Errorf("failed to fetch config: %v", err)
+	}
+	cfg, err = e.Render(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to render config: %v", err)
+	}
+	return e.Run(stage, cfg)
+}
+
+func (e *Engine) logger() *Logger {
+	if e.Logger == nil {
+		e.Logger = NewLogger(true)
+	}
+	return e.Logger
+}
+
+func (e *Engine) fetcher() *Fetcher {
+	if e.Fetcher == nil {
+		e.Fetcher = NewFetcher(e.logger())
+	}
+	return e.Fetcher
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+type recordingStage struct {
+	context StageContext
+	configs *[]types.Config
+}
+
+func (s recordingStage) Run(config types.Config) bool {
+	*s.configs = append(*s.configs, config)
+	return true
+}
+
+func (recordingStage) Name() string {
+	return "recording"
+}
+
+func TestEngine(t *testing.T) {
+	root, err := ioutil.TempDir("", "ignition-engine-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	configs := []types.Config{}
+	contexts := []StageContext{}
+	RegisterStage("recording", func(c StageContext) Stage {
+		contexts = append(contexts, c)
+		return recordingStage{context: c, configs: &configs}
+	})
+
+	mode := 0644
+	cfg := types.Config{
+		Ignition: types.Ignition{Version: types.MaxVersion.String()},
+		Storage: types.Storage{
+			Files: []types.File{{
+				Node: types.Node{
+					Filesystem: "root",
+					Path:       "/etc/hostname",
+				},
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.FileContents{Source: "data:,example"},
+					Mode:     &mode,
+				},
+			}},
+		},
+	}
+	RegisterProvider("test-provider", func(f *Fetcher) (types.Config, report.Report, error) {
+		return cfg, report.Report{}, nil
+	})
+
+	e := Engine{
+		Root:   root,
+		Logger: NewLogger(true),
+		Source: func(f *Fetcher) (types.Config, report.Report, error) {
+			return cfg, report.Report{}, nil
+		},
+	}
+
+	if err := e.Run("recording", cfg); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(contexts) != 1 || contexts[0].Root != root || contexts[0].Fetcher == nil {
+		t.Errorf("bad stage context: %+v", contexts)
+	}
+	if len(configs) != 1 || len(configs[0].Storage.Filesystems) != 1 ||
+		configs[0].Storage.Filesystems[0].Name != "root" || *configs[0].Storage.Filesystems[0].Path != root {
+		t.Errorf("stage did not get the root filesystem: %+v", configs)
+	}
+
+	if err := e.RunFromSource("files"); err != nil {
+		t.Fatalf("RunFromSource: %v", err)
+	}
+	contents, err := ioutil.ReadFile(filepath.Join(root, "etc/hostname"))
+	if err != nil || string(contents) != "example" {
+		t.Errorf("bad file written by files stage: %q, %v", contents, err)
+	}
+
+	if err := e.Run("nonexistent", cfg); err == nil {
+		t.Errorf("running an unknown stage should fail")
+	}
+
+	source, f, err := Provider("test-provider", e.Logger)
+	if err != nil {
+		t.Fatalf("Provider: %v", err)
+	}
+	e.Source = source
+	e.Fetcher = f
+	if _, err := e.FetchConfig(); err != nil {
+		t.Errorf("FetchConfig from provider: %v", err)
+	}
+	if _, _, err := Provider("nonexistent", e.Logger); err != ErrUnknownProvider {
+		t.Errorf("expected ErrUnknownProvider, got %v", err)
+	}
+}
+
+func TestRunFromSourceAppend(t *testing.T) {
+	root, err := ioutil.TempDir("", "ignition-engine-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	appended := `{"ignition": {"version": "2.1.0"}, "storage": {"files": [{"filesystem": "root", "path": "/etc/motd", "contents": {"source": "data:,appended"}, "mode": 420}]}}`
+	mode := 0644
+	cfg := types.Config{
+		Ignition: types.Ignition{
+			Version: types.MaxVersion.String(),
+			Config: types.IgnitionConfig{
+				Append: []types.ConfigReference{{
+					Source: "data:;base64," + base64.StdEncoding.EncodeToString([]byte(appended)),
+				}},
+			},
+		},
+		Storage: types.Storage{
+			Files: []types.File{{
+				Node: types.Node{
+					Filesystem: "root",
+					Path:       "/etc/hostname",
+				},
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.FileContents{Source: "data:,example"},
+					Mode:     &mode,
+				},
+			}},
+		},
+	}
+
+	// no Logger or Fetcher, so both are created as needed
+	e := Engine{
+		Root: root,
+		Source: func(f *Fetcher) (types.Config, report.Report, error) {
+			return cfg, report.Report{}, nil
+		},
+	}
+	if err := e.RunFromSource("files"); err != nil {
+		t.Fatalf("RunFromSource: %v", err)
+	}
+	if e.Logger == nil || e.Fetcher == nil {
+		t.Errorf("logger and fetcher weren't kept: %+v", e)
+	}
+	for path, expected := range map[string]string{
+		"etc/hostname": "example",
+		"etc/motd":     "appended",
+	} {
+		contents, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil || string(contents) != expected {
+			t.Errorf("bad %s: want %q, got %q, %v", path, expected, contents, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/resource"
+)
+
+// Fetcher fetches the resources referenced by a config (e.g. file contents)
+// over the schemes Ignition supports.
+type Fetcher struct {
+	f resource.Fetcher
+}
+
+// NewFetcher creates a new Fetcher which logs to l. It uses the proxies set in
+// the environment by default.
+func NewFetcher(l *Logger) *Fetcher {
+	return &Fetcher{f: resource.Fetcher{Logger: l.l, DefaultProxy: resource.ProxyFromEnvironment()}}
+}
+
+// SetS3RegionHint sets the region in which S3 buckets are looked up first.
+func (f *Fetcher) SetS3RegionHint(region string) {
+	f.f.S3RegionHint = region
+}
+
+// SetDefaultProxy sets the proxy settings used when fetching over HTTP(S)
+// which a config's proxy settings leave unset. It takes effect at the next
+// UpdateHttpTimeoutsAndCAs.
+func (f *Fetcher) SetDefaultProxy(proxy types.Proxy) {
+	f.f.DefaultProxy = proxy
+}
+
+// UpdateHttpTimeoutsAndCAs sets the timeouts, additional certificate
+// authorities and proxies used when fetching over HTTP(S), as a config's
+// ignition section would.
+func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, cas []types.CaReference, proxy types.Proxy) error {
+	return f.f.UpdateHttpTimeoutsAndCAs(timeouts, cas, proxy)
+}
+
+// Fetch fetches u into dest.
+func (f *Fetcher) Fetch(u url.URL, dest *os.File) error {
+	return f.f.Fetch(u, dest, resource.FetchOptions{})
+}
+
+// FetchToBuffer fetches u and returns its contents.
+func (f *Fetcher) FetchToBuffer(u url.URL) ([]byte, error) {
+	return f.f.FetchToBuffer(u, resource.FetchOptions{})
+}
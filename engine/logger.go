@@ -0,0 +1,92 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/coreos/ignition/internal/log"
+)
+
+// LoggerOps is the destination of a Logger's messages, one method per
+// priority. *syslog.Writer implements it.
+type LoggerOps interface {
+	Emerg(string) error
+	Alert(string) error
+	Crit(string) error
+	Err(string) error
+	Warning(string) error
+	Notice(string) error
+	Info(string) error
+	Debug(string) error
+	Close() error
+}
+
+// Logger logs the operations performed by the stages.
+type Logger struct {
+	l *log.Logger
+}
+
+// NewLogger creates a new Logger which logs to the system log, or to stdout
+// if logToStdout is true or the system log is unavailable.
+func NewLogger(logToStdout bool) *Logger {
+	l := log.New(logToStdout)
+	return &Logger{l: &l}
+}
+
+// NewLoggerWithOps creates a new Logger which logs using the supplied ops.
+func NewLoggerWithOps(ops LoggerOps) *Logger {
+	l := log.NewWithOps(ops)
+	return &Logger{l: &l}
+}
+
+// Close closes the logger's ops.
+func (l *Logger) Close() {
+	l.l.Close()
+}
+
+// Crit logs a message at critical priority.
+func (l *Logger) Crit(format string, a ...interface{}) error {
+	return l.l.Crit(format, a...)
+}
+
+// Err logs a message at error priority.
+func (l *Logger) Err(format string, a ...interface{}) error {
+	return l.l.Err(format, a...)
+}
+
+// Warning logs a message at warning priority.
+func (l *Logger) Warning(format string, a ...interface{}) error {
+	return l.l.Warning(format, a...)
+}
+
+// Notice logs a message at notice priority.
+func (l *Logger) Notice(format string, a ...interface{}) error {
+	return l.l.Notice(format, a...)
+}
+
+// Info logs a message at info priority.
+func (l *Logger) Info(format string, a ...interface{}) error {
+	return l.l.Info(format, a...)
+}
+
+// Debug logs a message at debug priority.
+func (l *Logger) Debug(format string, a ...interface{}) error {
+	return l.l.Debug(format, a...)
+}
+
+// LogOp calls and logs the supplied function as an operation with distinct
+// start/finish/fail log messages.
+func (l *Logger) LogOp(op func() error, format string, a ...interface{}) error {
+	return l.l.LogOp(op, format, a...)
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+	"github.com/coreos/ignition/internal/exec/stages"
+	"github.com/coreos/ignition/internal/exec/util"
+	"github.com/coreos/ignition/internal/oem"
+	"github.com/coreos/ignition/internal/resource"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown provider")
+)
+
+// Stage applies part of a config.
+type Stage interface {
+	Run(config types.Config) bool
+	Name() string
+}
+
+// StageContext holds what a stage needs to apply a config.
+type StageContext struct {
+	// Root is the directory the config is applied under.
+	Root    string
+	Logger  *Logger
+	Fetcher *Fetcher
+}
+
+// StageCreator creates a stage for each run of it.
+type StageCreator func(c StageContext) Stage
+
+// RegisterStage registers a stage named name, which can then be run by an
+// Engine (and by the ignition binary, if it is built with the registering
+// package). It panics if a stage with that name is already registered.
+func RegisterStage(name string, create StageCreator) {
+	stages.Register(stageCreator{name: name, create: create})
+}
+
+// Stages returns the names of all registered stages.
+func Stages() []string {
+	return stages.Names()
+}
+
+// stageCreator adapts a StageCreator to stages.StageCreator.
+type stageCreator struct {
+	name   string
+	create StageCreator
+}
+
+func (c stageCreator) Name() string {
+	return c.name
+}
+
+func (c stageCreator) Create(u util.Util) stages.Stage {
+	return c.create(StageContext{
+		Root:    u.DestDir,
+		Logger:  &Logger{l: u.Logger},
+		Fetcher: &Fetcher{f: u.Fetcher},
+	})
+}
+
+// RegisterProvider registers a provider named name, which fetches configs
+// with fetch. It panics if a provider with that name is already registered.
+func RegisterProvider(name string, fetch ConfigSource) {
+	oem.Register(name, func(f resource.Fetcher) (types.Config, report.Report, error) {
+		return fetch(&Fetcher{f: f})
+	}, nil)
+}
+
+// Providers returns the names of all registered providers.
+func Providers() []string {
+	return oem.Names()
+}
+
+// Provider returns a ConfigSource which fetches the config from the named
+// provider (e.g. "gce"), and a Fetcher set up for that provider.
+func Provider(name string, l *Logger) (ConfigSource, *Fetcher, error) {
+	c, ok := oem.Get(name)
+	if !ok {
+		return nil, nil, ErrUnknownProvider
+	}
+	f, err := c.NewFetcherFunc()(l.l)
+	if err != nil {
+		return nil, nil, err
+	}
+	fetch := c.FetchFunc()
+	source := func(f *Fetcher) (types.Config, report.Report, error) {
+		return fetch(f.f)
+	}
+	return source, &Fetcher{f: f}, nil
+}
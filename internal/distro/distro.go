@@ -38,6 +38,7 @@ var (
 	chrootCmd   = "/usr/bin/chroot"
 	groupaddCmd = "/usr/sbin/groupadd"
 	idCmd       = "/usr/bin/id"
+	losetupCmd  = "/usr/sbin/losetup"
 	mdadmCmd    = "/usr/sbin/mdadm"
 	mountCmd    = "/usr/bin/mount"
 	sgdiskCmd   = "/usr/sbin/sgdisk"
@@ -51,6 +52,10 @@ var (
 	swapMkfsCmd  = "/usr/sbin/mkswap"
 	vfatMkfsCmd  = "/usr/sbin/mkfs.vfat"
 	xfsMkfsCmd   = "/usr/sbin/mkfs.xfs"
+
+	// Decompression tools
+	xzCmd   = "/usr/bin/xz"
+	zstdCmd = "/usr/bin/zstd"
 )
 
 func DiskByIDDir() string       { return diskByIDDir }
@@ -65,6 +70,7 @@ func OEMLookasideDir() string   { return fromEnv("OEM_LOOKASIDE_DIR", oemLookasi
 func ChrootCmd() string   { return chrootCmd }
 func GroupaddCmd() string { return groupaddCmd }
 func IdCmd() string       { return idCmd }
+func LosetupCmd() string  { return losetupCmd }
 func MdadmCmd() string    { return mdadmCmd }
 func MountCmd() string    { return mountCmd }
 func SgdiskCmd() string   { return sgdiskCmd }
@@ -78,6 +84,9 @@ func SwapMkfsCmd() string  { return swapMkfsCmd }
 func VfatMkfsCmd() string  { return vfatMkfsCmd }
 func XfsMkfsCmd() string   { return xfsMkfsCmd }
 
+func XzCmd() string   { return xzCmd }
+func ZstdCmd() string { return zstdCmd }
+
 func fromEnv(nameSuffix, defaultValue string) string {
 	value := os.Getenv("IGNITION_" + nameSuffix)
 	if value != "" {
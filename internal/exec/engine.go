@@ -16,14 +16,17 @@ package exec
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/url"
+	"path/filepath"
 	"time"
 
 	"github.com/coreos/ignition/config"
 	"github.com/coreos/ignition/config/types"
 	"github.com/coreos/ignition/config/validate/report"
 	"github.com/coreos/ignition/internal/exec/stages"
+	execUtil "github.com/coreos/ignition/internal/exec/util"
 	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/oem"
 	"github.com/coreos/ignition/internal/providers"
@@ -35,6 +38,7 @@ import (
 
 const (
 	DefaultFetchTimeout = time.Minute
+	DefaultFetchWorkers = 4
 )
 
 // Engine represents the entity that fetches and executes a configuration.
@@ -44,23 +48,51 @@ type Engine struct {
 	Logger       *log.Logger
 	Root         string
 	OEMConfig    oem.Config
+
+	// Plan, if non-nil, causes Run to record the actions the stage would
+	// take into Plan rather than taking them. The config cache is not
+	// written while planning.
+	Plan *execUtil.Plan
+
+	// LedgerPath, if set, is where the ledger recording the operations which
+	// have completed is kept, relative to Root, so that they are skipped if
+	// the stage is run again. Only the stages in ledgerStages use it.
+	LedgerPath string
+
+	// ForceRerun, if true, causes the ledger to be ignored and replaced, so
+	// that every operation is run again.
+	ForceRerun bool
+
+	// FetchWorkers is the maximum number of remote files the files stage
+	// fetches at once.
+	FetchWorkers int
+
+	// DevicesReady, if true, means the devices referenced by the config are
+	// known to exist (e.g. loop devices attached by the caller), so the
+	// disks stage doesn't wait for them.
+	DevicesReady bool
+
+	// MergeConfigs, if true, causes configs layered over one another (the
+	// system base config, the user config and the configs it appends) to be
+	// combined with config.Merge rather than config.Append.
+	MergeConfigs bool
+
+	// StrictConfigs, if true, causes configs containing keys which aren't
+	// part of the spec to be rejected rather than only warned about.
+	StrictConfigs bool
 }
 
-// Run executes the stage of the given name. It returns true if the stage
-// successfully ran and false if there were any errors.
+// Run executes the stage of the given name, surrounded by its hooks. It
+// returns true if the stage successfully ran and false if there were any
+// errors. Operations done while acquiring the config aren't part of the
+// stage.
 func (e Engine) Run(stageName string) bool {
-	baseConfig := types.Config{
-		Ignition: types.Ignition{Version: types.MaxVersion.String()},
-		Storage: types.Storage{
-			Filesystems: []types.Filesystem{{
-				Name: "root",
-				Path: util.StringToPtr(e.Root),
-			}},
-		},
-	}
-
+	e.Logger.Journal().SetConfigSource("system base config")
 	systemBaseConfig, r, err := system.FetchBaseConfig(e.Logger)
-	e.logReport(r)
+	if err == nil {
+		err = e.checkStrict(&r)
+	}
+	e.LogReport(r)
 	if err != nil && err != providers.ErrNoProvider {
 		e.Logger.Crit("failed to acquire system base config: %v", err)
 		return false
@@ -71,8 +103,12 @@ func (e Engine) Run(stageName string) bool {
 	case nil:
 	case config.ErrCloudConfig, config.ErrScript, config.ErrEmpty:
 		e.Logger.Info("%v: ignoring user-provided config", err)
+		e.Logger.Journal().SetConfigSource("system default config")
 		cfg, r, err = system.FetchDefaultConfig(e.Logger)
-		e.logReport(r)
+		if err == nil {
+			err = e.checkStrict(&r)
+		}
+		e.LogReport(r)
 		if err != nil && err != providers.ErrNoProvider {
 			e.Logger.Crit("failed to acquire default config: %v", err)
 			return false
@@ -82,10 +118,113 @@ func (e Engine) Run(stageName string) bool {
 		return false
 	}
 
+	return e.runStage(stageName, e.combineConfigs(systemBaseConfig, cfg), f, true)
+}
+
+// RunStage executes the stage of the given name against cfg, to which the
+// root filesystem is added, using f to fetch any remote resources. Unlike Run,
+// it doesn't acquire a config or run hooks. It returns true if the stage
+// successfully ran and false if there were any errors.
+func (e Engine) RunStage(stageName string, cfg types.Config, f resource.Fetcher) bool {
+	return e.runStage(stageName, cfg, f, false)
+}
+
+// runStage implements Run and RunStage, running the stage's hooks around it
+// if hooks is set.
+func (e Engine) runStage(stageName string, cfg types.Config, f resource.Fetcher, hooks bool) bool {
+	creator := stages.Get(stageName)
+	if creator == nil {
+		e.Logger.Crit("unknown stage %q", stageName)
+		return false
+	}
+
+	baseConfig := types.Config{
+		Ignition: types.Ignition{Version: types.MaxVersion.String()},
+		Storage: types.Storage{
+			Filesystems: []types.Filesystem{{
+				Name: "root",
+				Path: util.StringToPtr(e.Root),
+			}},
+		},
+	}
+
+	e.Logger.Journal().BeginStage(stageName)
 	e.Logger.PushPrefix(stageName)
 	defer e.Logger.PopPrefix()
 
-	return stages.Get(stageName).Create(e.Logger, e.Root, f).Run(config.Append(baseConfig, config.Append(systemBaseConfig, cfg)))
+	ledger := e.openLedger(stageName)
+	ledger.BeginStage(stageName)
+	if e.Plan != nil {
+		e.Plan.BeginStage(stageName)
+	}
+
+	if hooks {
+		if err := e.runHooks("pre", stageName); err != nil {
+			e.Logger.Crit("pre-%s hook failed: %v", stageName, err)
+			return false
+		}
+	}
+	if !creator.Create(execUtil.Util{
+		DestDir:      e.Root,
+		Fetcher:      f,
+		Plan:         e.Plan,
+		Ledger:       ledger,
+		FetchWorkers: e.FetchWorkers,
+		DevicesReady: e.DevicesReady,
+		Logger:       e.Logger,
+	}).Run(config.Append(baseConfig, cfg)) {
+		return false
+	}
+	if hooks {
+		if err := e.runHooks("post", stageName); err != nil {
+			e.Logger.Crit("post-%s hook failed: %v", stageName, err)
+			return false
+		}
+	}
+	return true
+}
+
+// ledgerStages are the stages whose operations are recorded in the ledger.
+// They run once the target root is mounted, so that the ledger is kept on it.
+// The disks stage runs before that, and instead resumes by comparing the
+// partitions already on each disk with the config.
+var ledgerStages = map[string]bool{
+	"files": true,
+}
+
+// openLedger returns the ledger for the given stage, or nil if the stage
+// doesn't use one or there is no ledger.
+func (e Engine) openLedger(stageName string) *execUtil.Ledger {
+	if e.LedgerPath == "" || e.Plan != nil || !ledgerStages[stageName] {
+		return nil
+	}
+	path := filepath.Join(e.Root, e.LedgerPath)
+	if e.ForceRerun {
+		return execUtil.NewLedger(path)
+	}
+	ledger, err := execUtil.OpenLedger(path)
+	if err != nil {
+		e.Logger.Err("unable to read existing ledger, starting a new one: %v", err)
+	}
+	return ledger
+}
+
+// combineConfigs layers newConfig over oldConfig, merging them if
+// e.MergeConfigs is set and appending them otherwise.
+func (e Engine) combineConfigs(oldConfig, newConfig types.Config) types.Config {
+	if e.MergeConfigs {
+		return config.Merge(oldConfig, newConfig)
+	}
+	return config.Append(oldConfig, newConfig)
+}
+
+// checkStrict returns config.ErrInvalid if e.StrictConfigs is set and r reports
+// keys which aren't part of the spec, which are then errors in r.
+func (e Engine) checkStrict(r *report.Report) error {
+	if e.StrictConfigs && config.RejectUnknownKeys(r) {
+		return config.ErrInvalid
+	}
+	return nil
 }
 
 // acquireConfig returns the configuration, first checking a local cache
@@ -96,6 +235,14 @@ func (e *Engine) acquireConfig() (cfg types.Config, f resource.Fetcher, err erro
 		e.Logger.Crit("failed to generate fetcher: %s", err)
 		return
 	}
+	f.Keyring, err = trustedKeys()
+	if err != nil {
+		e.Logger.Crit("failed to read trusted keys: %v", err)
+		return
+	}
+	// Proxy settings on the cmdline take precedence over the environment,
+	// and those of the config over both
+	f.DefaultProxy = resource.OverrideProxy(resource.ProxyFromEnvironment(), cmdline.Proxy(e.Logger))
 
 	// First try read the config @ e.ConfigCache.
 	b, err := ioutil.ReadFile(e.ConfigCache)
@@ -105,7 +252,7 @@ func (e *Engine) acquireConfig() (cfg types.Config, f resource.Fetcher, err erro
 		}
 		// Create an http client and fetcher with the timeouts from the cached
 		// config
-		err = f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities)
+		err = f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy)
 		if err != nil {
 			e.Logger.Crit("failed to update timeouts and CAs for fetcher: %v", err)
 			return
@@ -116,14 +263,25 @@ func (e *Engine) acquireConfig() (cfg types.Config, f resource.Fetcher, err erro
 	// Create a new http client and fetcher with the timeouts set via the flags,
 	// since we don't have a config with timeout values we can use
 	timeout := int(e.FetchTimeout.Seconds())
-	err = f.UpdateHttpTimeoutsAndCAs(types.Timeouts{HTTPTotal: &timeout}, nil)
+	err = f.UpdateHttpTimeoutsAndCAs(types.Timeouts{HTTPTotal: &timeout}, nil, types.Proxy{})
 	if err != nil {
 		e.Logger.Crit("failed to update timeouts and CAs for fetcher: %v", err)
 		return
 	}
 
+	signedOnly, err := signedConfigsOnly()
+	if err != nil {
+		e.Logger.Crit("failed to read signature policy: %v", err)
+		return
+	}
+	if signedOnly && len(f.Keyring) == 0 {
+		err = fmt.Errorf("only signed configs are accepted, but no keys are trusted")
+		e.Logger.Crit("%v", err)
+		return
+	}
+
 	// (Re)Fetch the config if the cache is unreadable.
-	cfg, f, err = e.fetchProviderConfig(f)
+	cfg, f, err = e.fetchProviderConfig(f, signedOnly)
 	if err != nil {
 		e.Logger.Crit("failed to fetch config: %s", err)
 		return
@@ -131,7 +289,7 @@ func (e *Engine) acquireConfig() (cfg types.Config, f resource.Fetcher, err erro
 
 	// Update the http client to use the timeouts and CAs from the newly fetched
 	// config
-	err = f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities)
+	err = f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy)
 	if err != nil {
 		e.Logger.Crit("failed to update timeouts and CAs for fetcher: %v", err)
 		return
@@ -143,7 +301,10 @@ func (e *Engine) acquireConfig() (cfg types.Config, f resource.Fetcher, err erro
 		return
 	}
 
-	// Populate the config cache.
+	// Populate the config cache, unless only planning.
+	if e.Plan != nil {
+		return
+	}
 	b, err = json.Marshal(cfg)
 	if err != nil {
 		e.Logger.Crit("failed to marshal cached config: %v", err)
@@ -163,33 +324,52 @@ func (e *Engine) acquireConfig() (cfg types.Config, f resource.Fetcher, err erro
 // checks for a user config in the system config dir. If that is also missing,
 // it checks the config engine's provider. An error is returned if the provider
 // is unavailable. This will also render the config (see renderConfig) before
-// returning.
-func (e *Engine) fetchProviderConfig(f resource.Fetcher) (types.Config, resource.Fetcher, error) {
-	fetchers := []providers.FuncFetchConfig{
-		cmdline.FetchConfig,
-		system.FetchConfig,
-		e.OEMConfig.FetchFunc(),
+// returning. If f has trusted keys, the cmdline config must be signed. The
+// provider's config can't be, so if signedOnly is set it is refused, and
+// otherwise it is accepted with a warning.
+func (e *Engine) fetchProviderConfig(f resource.Fetcher, signedOnly bool) (types.Config, resource.Fetcher, error) {
+	fetchers := []struct {
+		source string
+		fetch  providers.FuncFetchConfig
+		// signed is whether the fetcher checks the config's signature
+		// itself, or reads it from somewhere as trusted as the keys.
+		signed bool
+	}{
+		{"cmdline", cmdline.FetchConfig, true},
+		{"system user config", system.FetchConfig, true},
+		{"provider " + e.OEMConfig.Name(), e.OEMConfig.FetchFunc(), false},
 	}
 
 	var cfg types.Config
 	var r report.Report
 	var err error
 	for _, fetcher := range fetchers {
-		cfg, r, err = fetcher(f)
+		e.Logger.Journal().SetConfigSource(fetcher.source)
+		if !fetcher.signed && signedOnly {
+			err = fmt.Errorf("%s can't provide signed configs, and only signed configs are accepted", fetcher.source)
+			break
+		}
+		cfg, r, err = fetcher.fetch(f)
+		if err == nil && !fetcher.signed && len(f.Keyring) != 0 {
+			e.Logger.Warning("accepting unsigned config from %s", fetcher.source)
+		}
 		if err != providers.ErrNoProvider {
 			// successful, or failed on another error
 			break
 		}
 	}
 
-	e.logReport(r)
+	if err == nil {
+		err = e.checkStrict(&r)
+	}
+	e.LogReport(r)
 	if err != nil {
 		return types.Config{}, f, err
 	}
 
 	// Replace the HTTP client in the fetcher to be configured with the
 	// timeouts of the config
-	err = f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities)
+	err = f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy)
 	if err != nil {
 		return types.Config{}, f, err
 	}
@@ -197,6 +377,23 @@ func (e *Engine) fetchProviderConfig(f resource.Fetcher) (types.Config, resource
 	return e.renderConfig(cfg, f)
 }
 
+// RenderConfig renders a config which was acquired by other means than Run
+// (see renderConfig), after configuring f with the config's timeouts and CAs.
+// f is then configured with those of the rendered config.
+func (e *Engine) RenderConfig(cfg types.Config, f resource.Fetcher) (types.Config, resource.Fetcher, error) {
+	if err := f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy); err != nil {
+		return types.Config{}, f, err
+	}
+	cfg, f, err := e.renderConfig(cfg, f)
+	if err != nil {
+		return types.Config{}, f, err
+	}
+	if err := f.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy); err != nil {
+		return types.Config{}, f, err
+	}
+	return cfg, f, nil
+}
+
 // renderConfig evaluates "ignition.config.replace" and "ignition.config.append"
 // in the given config and returns the result. If "ignition.config.replace" is
 // set, the referenced and evaluted config will be returned. Otherwise, if
@@ -213,7 +410,7 @@ func (e *Engine) renderConfig(cfg types.Config, f resource.Fetcher) (types.Confi
 
 		// Replace the HTTP client in the fetcher to be configured with the
 		// timeouts of the new config
-		err = f.UpdateHttpTimeoutsAndCAs(newCfg.Ignition.Timeouts, newCfg.Ignition.Security.TLS.CertificateAuthorities)
+		err = f.UpdateHttpTimeoutsAndCAs(newCfg.Ignition.Timeouts, newCfg.Ignition.Security.TLS.CertificateAuthorities, newCfg.Ignition.Proxy)
 		if err != nil {
 			return types.Config{}, f, err
 		}
@@ -231,8 +428,8 @@ func (e *Engine) renderConfig(cfg types.Config, f resource.Fetcher) (types.Confi
 		// Append the old config with the new config before the new config has
 		// been rendered, so we can use the new config's timeouts and CAs when
 		// fetching more configs.
-		cfgForFetcherSettings := config.Append(appendedCfg, newCfg)
-		err = f.UpdateHttpTimeoutsAndCAs(cfgForFetcherSettings.Ignition.Timeouts, cfgForFetcherSettings.Ignition.Security.TLS.CertificateAuthorities)
+		cfgForFetcherSettings := e.combineConfigs(appendedCfg, newCfg)
+		err = f.UpdateHttpTimeoutsAndCAs(cfgForFetcherSettings.Ignition.Timeouts, cfgForFetcherSettings.Ignition.Security.TLS.CertificateAuthorities, cfgForFetcherSettings.Ignition.Proxy)
 		if err != nil {
 			return types.Config{}, f, err
 		}
@@ -242,31 +439,55 @@ func (e *Engine) renderConfig(cfg types.Config, f resource.Fetcher) (types.Confi
 			return types.Config{}, f, err
 		}
 
-		appendedCfg = config.Append(appendedCfg, newCfg)
+		appendedCfg = e.combineConfigs(appendedCfg, newCfg)
 	}
 	return appendedCfg, f, nil
 }
 
-// fetchReferencedConfig fetches and parses the requested config.
+// fetchReferencedConfig fetches and parses the requested config, trying its
+// mirrors in order if the source can't be fetched or fails verification. If f
+// has trusted keys, the config must have a hash or a signature.
 func (e *Engine) fetchReferencedConfig(cfgRef types.ConfigReference, f resource.Fetcher) (types.Config, error) {
-	u, err := url.Parse(cfgRef.Source)
-	if err != nil {
-		return types.Config{}, err
+	if len(f.Keyring) != 0 && cfgRef.Verification.Hash == nil && cfgRef.Verification.Signature == nil {
+		return types.Config{}, fmt.Errorf("config %s has neither a hash nor a signature: %v", cfgRef.Source, util.ErrSignatureRequired)
+	}
+
+	sources := []url.URL{}
+	for _, s := range append([]string{cfgRef.Source}, mirrorStrings(cfgRef.Mirrors)...) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return types.Config{}, err
+		}
+		sources = append(sources, *u)
 	}
-	rawCfg, err := f.FetchToBuffer(*u, resource.FetchOptions{
-		Headers: resource.ConfigHeaders,
+
+	rawCfg, u, err := f.FetchToBufferFromAny(sources, resource.FetchOptions{
+		Headers: resource.AddHeaders(resource.ConfigHeaders, cfgRef.HTTPHeaders),
+	}, func(_ url.URL, raw []byte) error {
+		if err := util.AssertValid(cfgRef.Verification, raw); err != nil {
+			return err
+		}
+		return f.AssertSigned(cfgRef.Verification, cfgRef.HTTPHeaders, raw)
 	})
 	if err != nil {
 		return types.Config{}, err
 	}
 	e.Logger.Debug("fetched referenced config: %s", string(rawCfg))
 
-	if err := util.AssertValid(cfgRef.Verification, rawCfg); err != nil {
-		return types.Config{}, err
+	source := u.String()
+	if u.Scheme == "data" {
+		source = "data url"
+	}
+	e.Logger.Journal().SetConfigSource("referenced config " + source)
+	if err := e.Logger.Journal().RecordConfig(rawCfg); err != nil {
+		e.Logger.Err("failed to write journal: %v", err)
 	}
 
 	cfg, r, err := config.Parse(rawCfg)
-	e.logReport(r)
+	if err == nil {
+		err = e.checkStrict(&r)
+	}
+	e.LogReport(r)
 	if err != nil {
 		return types.Config{}, err
 	}
@@ -274,7 +495,16 @@ func (e *Engine) fetchReferencedConfig(cfgRef types.ConfigReference, f resource.
 	return cfg, nil
 }
 
-func (e Engine) logReport(r report.Report) {
+func mirrorStrings(mirrors []types.ConfigReferenceMirror) []string {
+	ss := make([]string, 0, len(mirrors))
+	for _, m := range mirrors {
+		ss = append(ss, string(m))
+	}
+	return ss
+}
+
+// LogReport logs each entry of r at the priority matching its kind.
+func (e Engine) LogReport(r report.Report) {
 	for _, entry := range r.Entries {
 		switch entry.Kind {
 		case report.EntryError:
@@ -0,0 +1,89 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/coreos/ignition/internal/distro"
+	execUtil "github.com/coreos/ignition/internal/exec/util"
+)
+
+// Hooks are executables which distros can install to run before and after
+// a stage, e.g. to load a kernel module before the disks stage. They live in
+// hooks/pre-<stage>.d and hooks/post-<stage>.d under the system config dir,
+// and are run in lexical order of their names.
+const (
+	hooksDir = "hooks"
+)
+
+// hookPaths returns the paths of the executables in the hook directory with
+// the given name, in lexical order. A missing directory has no hooks.
+func hookPaths(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(filepath.Join(distro.SystemConfigDir(), hooksDir, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, info := range infos {
+		path := filepath.Join(distro.SystemConfigDir(), hooksDir, dir, info.Name())
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(path); err != nil {
+				return nil, err
+			}
+		}
+		if !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// runHooks runs the hooks of the given kind ("pre" or "post") for the named
+// stage. The hooks get the stage name, root and config cache path in the
+// IGNITION_STAGE, IGNITION_ROOT and IGNITION_CONFIG_CACHE environment
+// variables. The first hook to fail stops the rest from running.
+func (e Engine) runHooks(kind, stageName string) error {
+	paths, err := hookPaths(fmt.Sprintf("%s-%s.d", kind, stageName))
+	if err != nil {
+		return fmt.Errorf("failed to find %s-%s hooks: %v", kind, stageName, err)
+	}
+
+	u := execUtil.Util{
+		DestDir: e.Root,
+		Plan:    e.Plan,
+		Logger:  e.Logger,
+	}
+	for _, path := range paths {
+		cmd := exec.Command(path)
+		cmd.Env = append(os.Environ(),
+			"IGNITION_STAGE="+stageName,
+			"IGNITION_ROOT="+e.Root,
+			"IGNITION_CONFIG_CACHE="+e.ConfigCache,
+		)
+		if _, err := u.RunCmdAs(execUtil.ActionHook, path, cmd, "running %s-%s hook %q", kind, stageName, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/internal/log"
+)
+
+func TestHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition-hooks-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("IGNITION_SYSTEM_CONFIG_DIR", dir)
+	defer os.Unsetenv("IGNITION_SYSTEM_CONFIG_DIR")
+
+	out := filepath.Join(dir, "out")
+	writeHook := func(name, contents string, mode os.FileMode) {
+		path := filepath.Join(dir, hooksDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeHook("pre-files.d/20-second", "#!/bin/sh\necho second >> "+out+"\n", 0755)
+	writeHook("pre-files.d/10-first", "#!/bin/sh\necho \"$IGNITION_STAGE $IGNITION_ROOT $IGNITION_CONFIG_CACHE\" >> "+out+"\n", 0755)
+	writeHook("pre-files.d/30-not-executable", "#!/bin/sh\necho nope >> "+out+"\n", 0644)
+	writeHook("post-files.d/10-fail", "#!/bin/sh\nexit 3\n", 0755)
+
+	logger := log.New(true)
+	e := Engine{
+		Root:        "/sysroot",
+		ConfigCache: "/run/ignition.json",
+		Logger:      &logger,
+	}
+
+	if err := e.runHooks("pre", "files"); err != nil {
+		t.Fatalf("pre hooks: %v", err)
+	}
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "files /sysroot /run/ignition.json\nsecond\n"; string(b) != expected {
+		t.Errorf("bad hook output: want %q, got %q", expected, b)
+	}
+
+	if err := e.runHooks("post", "files"); err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("expected the failing post hook to fail, got %v", err)
+	}
+	if err := e.runHooks("pre", "disks"); err != nil {
+		t.Errorf("missing hook directory: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	_ "github.com/coreos/ignition/internal/exec/stages/disks"
+	_ "github.com/coreos/ignition/internal/exec/stages/files"
+	execUtil "github.com/coreos/ignition/internal/exec/util"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+)
+
+func TestLedgerAcrossRestarts(t *testing.T) {
+	root, err := ioutil.TempDir("", "ignition-ledger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	ledgerPath := filepath.Join(root, execUtil.LedgerPath)
+
+	appendHello := types.File{
+		Node: types.Node{Filesystem: "root", Path: "/etc/motd"},
+		FileEmbedded1: types.FileEmbedded1{
+			Append:   true,
+			Contents: types.FileContents{Source: "data:,hello%0A"},
+		},
+	}
+	cfg := types.Config{
+		Ignition: types.Ignition{Version: types.MaxVersion.String()},
+		Storage:  types.Storage{Files: []types.File{appendHello, appendHello}},
+	}
+
+	logger := log.New(true)
+	f := resource.Fetcher{Logger: &logger}
+	boot := func(first bool) {
+		// each stage runs in a new process
+		for _, stage := range []string{"disks", "files"} {
+			e := Engine{
+				Root:         root,
+				Logger:       &logger,
+				LedgerPath:   execUtil.LedgerPath,
+				DevicesReady: true,
+			}
+			if !e.RunStage(stage, cfg, f) {
+				t.Fatalf("%s stage failed", stage)
+			}
+			if _, err := os.Stat(ledgerPath); first && stage == "disks" && !os.IsNotExist(err) {
+				// the target root isn't mounted yet when the disks
+				// stage runs, so it mustn't write a ledger there
+				t.Fatalf("disks stage wrote the ledger: %v", err)
+			}
+		}
+	}
+
+	// the second and third boots resume an interrupted first boot
+	boot(true)
+	boot(false)
+	boot(false)
+
+	data, err := ioutil.ReadFile(filepath.Join(root, "etc/motd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello\nhello\n" {
+		t.Errorf("bad contents: %q", data)
+	}
+	ledger, err := execUtil.OpenLedger(ledgerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger.Completed) != 2 {
+		t.Errorf("expected both appends in the ledger, got %v", ledger.Completed)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/ignition/internal/distro"
+	"github.com/coreos/ignition/internal/util"
+)
+
+// Trusted keys are PEM-encoded public keys which distros can install to
+// require that configs are signed. They live in *.pem files in trusted-keys
+// under the system config dir. Configs from the platform's provider have
+// nowhere to keep a signature, so they are only refused if the distro also
+// installs a signed-configs-only file there.
+const (
+	trustedKeysDir = "trusted-keys"
+	signedOnlyFile = "signed-configs-only"
+)
+
+// trustedKeys returns the keys in the trusted keys dir, read in lexical order
+// of their file names. A missing directory has no keys.
+func trustedKeys() (util.Keyring, error) {
+	dir := filepath.Join(distro.SystemConfigDir(), trustedKeysDir)
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	keyring := util.Keyring{}
+	for _, info := range infos {
+		if !strings.HasSuffix(info.Name(), ".pem") {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := util.ParsePublicKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		keyring = append(keyring, keys...)
+	}
+	return keyring, nil
+}
+
+// signedConfigsOnly returns whether the distro's policy is to refuse configs
+// which can't be signed.
+func signedConfigsOnly() (bool, error) {
+	_, err := os.Stat(filepath.Join(distro.SystemConfigDir(), trustedKeysDir, signedOnlyFile))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/oem"
+	"github.com/coreos/ignition/internal/resource"
+	"github.com/coreos/ignition/internal/util"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+func TestSignedReferencedConfigs(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(data []byte) *string {
+		sum := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := dataurl.EncodeBytes(sig)
+		return &u
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "ignition-signatures-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("IGNITION_SYSTEM_CONFIG_DIR", dir)
+	defer os.Unsetenv("IGNITION_SYSTEM_CONFIG_DIR")
+
+	keyring, err := trustedKeys()
+	if err != nil || len(keyring) != 0 {
+		t.Fatalf("expected no keys without a trusted keys dir, got %v, %v", keyring, err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, trustedKeysDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, trustedKeysDir, "README"), []byte("not a key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, trustedKeysDir, "10-test.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err = trustedKeys()
+	if err != nil || len(keyring) != 1 {
+		t.Fatalf("expected one key, got %v, %v", keyring, err)
+	}
+
+	raw := []byte(`{"ignition": {"version": "2.2.0-experimental"}}`)
+	source := dataurl.EncodeBytes(raw)
+	sum := sha512.Sum512(raw)
+	hash := "sha512-" + hex.EncodeToString(sum[:])
+
+	logger := log.New(true)
+	e := Engine{Logger: &logger}
+	tests := []struct {
+		keyring util.Keyring
+		ref     types.ConfigReference
+		ok      bool
+	}{
+		// without keys, signatures aren't needed and can't be checked
+		{nil, types.ConfigReference{Source: source}, true},
+		{nil, types.ConfigReference{Source: source, Verification: types.Verification{Signature: sign([]byte("other"))}}, true},
+		{keyring, types.ConfigReference{Source: source, Verification: types.Verification{Signature: sign(raw)}}, true},
+		{keyring, types.ConfigReference{Source: source, Verification: types.Verification{Signature: sign([]byte("other"))}}, false},
+		{keyring, types.ConfigReference{Source: source}, false},
+		// a hash from the (signed) referencing config is enough
+		{keyring, types.ConfigReference{Source: source, Verification: types.Verification{Hash: &hash}}, true},
+	}
+
+	for i, test := range tests {
+		_, err := e.fetchReferencedConfig(test.ref, resource.Fetcher{Logger: &logger, Keyring: test.keyring})
+		if (err == nil) != test.ok {
+			t.Errorf("#%d: bad error: %v", i, err)
+		}
+	}
+}
+
+func TestSignedProviderConfigs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition-signatures-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("IGNITION_SYSTEM_CONFIG_DIR", dir)
+	defer os.Unsetenv("IGNITION_SYSTEM_CONFIG_DIR")
+
+	cfgPath := filepath.Join(dir, "provider.ign")
+	if err := ioutil.WriteFile(cfgPath, []byte(`{"ignition": {"version": "2.2.0-experimental"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("IGNITION_CONFIG_FILE", cfgPath)
+	defer os.Unsetenv("IGNITION_CONFIG_FILE")
+
+	signedOnly, err := signedConfigsOnly()
+	if err != nil || signedOnly {
+		t.Fatalf("expected no policy without a policy file, got %v, %v", signedOnly, err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, trustedKeysDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, trustedKeysDir, signedOnlyFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	signedOnly, err = signedConfigsOnly()
+	if err != nil || !signedOnly {
+		t.Fatalf("expected the policy file to be found, got %v, %v", signedOnly, err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.New(true)
+	e := Engine{Logger: &logger, OEMConfig: oem.MustGet("file")}
+	tests := []struct {
+		keyring    util.Keyring
+		signedOnly bool
+		ok         bool
+	}{
+		{nil, false, true},
+		// trusting keys doesn't refuse the provider by itself
+		{util.Keyring{&key.PublicKey}, false, true},
+		{util.Keyring{&key.PublicKey}, true, false},
+	}
+
+	for i, test := range tests {
+		_, _, err := e.fetchProviderConfig(resource.Fetcher{Logger: &logger, Keyring: test.keyring}, test.signedOnly)
+		if (err == nil) != test.ok {
+			t.Errorf("#%d: bad error: %v", i, err)
+		}
+	}
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/coreos/ignition/internal/distro"
 	"github.com/coreos/ignition/internal/exec/stages"
 	"github.com/coreos/ignition/internal/exec/util"
-	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/resource"
 	"github.com/coreos/ignition/internal/sgdisk"
 	"github.com/coreos/ignition/internal/systemd"
@@ -48,13 +47,9 @@ func init() {
 
 type creator struct{}
 
-func (creator) Create(logger *log.Logger, root string, f resource.Fetcher) stages.Stage {
+func (creator) Create(u util.Util) stages.Stage {
 	return &stage{
-		Util: util.Util{
-			DestDir: root,
-			Logger:  logger,
-			Fetcher: f,
-		},
+		Util: u,
 	}
 }
 
@@ -120,8 +115,13 @@ func (s stage) Run(config types.Config) bool {
 	// Test case: boot failure in coreos.ignition.*.btrfsroot kola test.
 	//
 	// Additionally, partitioning (and possibly creating raid) suffers
-	// the same problem. To be safe, always settle.
-	if _, err := s.Logger.LogCmd(
+	// the same problem. To be safe, always settle, unless the devices
+	// aren't being managed by udev and systemd (e.g. when building disk
+	// images from loop devices).
+	if s.DevicesReady {
+		return true
+	}
+	if _, err := s.RunCmdAs(util.ActionSettle, "",
 		exec.Command(distro.UdevadmCmd(), "settle"),
 		"waiting for udev to settle",
 	); err != nil {
@@ -159,9 +159,19 @@ func (s stage) createDeviceAliases(devs []string) error {
 }
 
 // waitOnDevicesAndCreateAliases simply wraps waitOnDevices and createDeviceAliases.
+// When planning, the devices may not exist, so neither is done. If the devices
+// are known to be ready (e.g. loop devices), they aren't waited on, since
+// systemd may not be managing them.
 func (s stage) waitOnDevicesAndCreateAliases(devs []string, ctxt string) error {
-	if err := s.waitOnDevices(devs, ctxt); err != nil {
-		return err
+	if s.Planning() {
+		s.Logger.Info("planning: not waiting on or aliasing %s devs %v", ctxt, devs)
+		return nil
+	}
+
+	if !s.DevicesReady {
+		if err := s.waitOnDevices(devs, ctxt); err != nil {
+			return err
+		}
 	}
 
 	if err := s.createDeviceAliases(devs); err != nil {
@@ -192,13 +202,29 @@ func (s stage) createPartitions(config types.Config) error {
 		devAlias := util.DeviceAlias(string(dev.Device))
 
 		err := s.Logger.LogOp(func() error {
-			op := sgdisk.Begin(s.Logger, devAlias)
-			if dev.WipeTable {
+			existing, err := s.readPartitions(devAlias)
+			if err != nil {
+				return err
+			}
+			wipe, parts, err := pendingPartitions(dev, existing)
+			if err != nil {
+				return err
+			}
+			if !wipe && len(parts) == 0 {
+				s.Logger.Info("partitions on %q already match the config, skipping", devAlias)
+				return nil
+			}
+			if skipped := len(dev.Partitions) - len(parts); skipped > 0 {
+				s.Logger.Info("%d partitions on %q already match the config, skipping them", skipped, devAlias)
+			}
+
+			op := sgdisk.Begin(s.Util, devAlias)
+			if wipe {
 				s.Logger.Info("wiping partition table requested on %q", devAlias)
 				op.WipeTable(true)
 			}
 
-			for _, part := range dev.Partitions {
+			for _, part := range parts {
 				op.CreatePartition(sgdisk.Partition{
 					Number:   part.Number,
 					Length:   uint64(part.Size),
@@ -222,6 +248,76 @@ func (s stage) createPartitions(config types.Config) error {
 	return nil
 }
 
+// readPartitions returns the partitions already on dev. When planning, the
+// device may not exist, and is treated as though it had no partitions.
+func (s stage) readPartitions(dev string) ([]sgdisk.Partition, error) {
+	var parts []sgdisk.Partition
+	err := s.Logger.LogOp(func() error {
+		var err error
+		parts, err = sgdisk.ReadTable(dev)
+		return err
+	}, "reading partition table of %q", dev)
+	if err != nil && s.Planning() {
+		s.Logger.Info("planning: assuming %q has no partitions", dev)
+		return nil, nil
+	}
+	return parts, err
+}
+
+// pendingPartitions returns whether the partition table of disk still has to
+// be wiped and which of its partitions still have to be created, given the
+// partitions already on it, so that a disks stage which was interrupted can be
+// run again without failing on, or recreating, partitions it already created.
+// Partitions are identified by number or, if their number is 0, by label, and
+// match if the fields set in the config agree. A table which is to be wiped is
+// left alone only if it holds exactly the configured partitions.
+func pendingPartitions(disk types.Disk, existing []sgdisk.Partition) (bool, []types.Partition, error) {
+	claimed := map[int]bool{}
+	find := func(p types.Partition) (sgdisk.Partition, bool) {
+		for _, e := range existing {
+			if claimed[e.Number] {
+				continue
+			}
+			if (p.Number != 0 && e.Number == p.Number) ||
+				(p.Number == 0 && p.Label != "" && e.Label == p.Label) {
+				claimed[e.Number] = true
+				return e, true
+			}
+		}
+		return sgdisk.Partition{}, false
+	}
+
+	pending := []types.Partition{}
+	for _, p := range disk.Partitions {
+		e, ok := find(p)
+		switch {
+		case ok && partitionMatches(e, p):
+			continue
+		case ok && p.Number != 0 && !disk.WipeTable:
+			return false, nil, fmt.Errorf("partition %d on %q already exists and doesn't match the config", p.Number, disk.Device)
+		}
+		pending = append(pending, p)
+	}
+
+	if !disk.WipeTable {
+		return false, pending, nil
+	}
+	if len(disk.Partitions) > 0 && len(pending) == 0 && len(existing) == len(disk.Partitions) {
+		return false, nil, nil
+	}
+	return true, disk.Partitions, nil
+}
+
+// partitionMatches returns whether the existing partition e agrees with the
+// fields set for p in the config.
+func partitionMatches(e sgdisk.Partition, p types.Partition) bool {
+	return e.Label == p.Label &&
+		(p.Start == 0 || e.Offset == uint64(p.Start)) &&
+		(p.Size == 0 || e.Length == uint64(p.Size)) &&
+		(p.TypeGUID == "" || strings.EqualFold(e.TypeGUID, p.TypeGUID)) &&
+		(p.GUID == "" || strings.EqualFold(e.GUID, p.GUID))
+}
+
 // createRaids creates the raid arrays described in config.Storage.Raid.
 func (s stage) createRaids(config types.Config) error {
 	if len(config.Storage.Raid) == 0 {
@@ -263,7 +359,7 @@ func (s stage) createRaids(config types.Config) error {
 			args = append(args, util.DeviceAlias(string(dev)))
 		}
 
-		if _, err := s.Logger.LogCmd(
+		if _, err := s.RunCmd(md.Name,
 			exec.Command(distro.MdadmCmd(), args...),
 			"creating %q", md.Name,
 		); err != nil {
@@ -310,7 +406,13 @@ func (s stage) createFilesystems(config types.Config) error {
 func (s stage) createFilesystem(fs types.Mount) error {
 	info, err := s.readFilesystemInfo(fs)
 	if err != nil {
-		return err
+		if !s.Planning() {
+			return err
+		}
+		// The device may not exist where the plan is being made; plan as
+		// though it were blank.
+		s.Logger.Info("planning: assuming %q has no filesystem", fs.Device)
+		info = filesystemInfo{}
 	}
 
 	if fs.Create != nil {
@@ -395,7 +497,7 @@ func (s stage) createFilesystem(fs types.Mount) error {
 
 	devAlias := util.DeviceAlias(string(fs.Device))
 	args = append(args, devAlias)
-	if _, err := s.Logger.LogCmd(
+	if _, err := s.RunCmd(string(fs.Device),
 		exec.Command(mkfs, args...),
 		"creating %q filesystem on %q",
 		fs.Format, devAlias,
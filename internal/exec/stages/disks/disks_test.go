@@ -0,0 +1,110 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/sgdisk"
+)
+
+func TestPendingPartitions(t *testing.T) {
+	type in struct {
+		disk     types.Disk
+		existing []sgdisk.Partition
+	}
+	type out struct {
+		wipe  bool
+		parts []types.Partition
+		ok    bool
+	}
+
+	root := types.Partition{Number: 1, Label: "ROOT", Size: 4194304, TypeGUID: "0fc63daf-8483-4772-8e79-3d69d8477de4"}
+	data := types.Partition{Number: 2, Label: "DATA"}
+	unnumbered := types.Partition{Label: "VAR"}
+	rootOnDisk := sgdisk.Partition{Number: 1, Label: "ROOT", Offset: 2048, Length: 4194304, TypeGUID: "0FC63DAF-8483-4772-8E79-3D69D8477DE4"}
+	dataOnDisk := sgdisk.Partition{Number: 2, Label: "DATA", Offset: 4196352, Length: 1024}
+	varOnDisk := sgdisk.Partition{Number: 3, Label: "VAR", Offset: 4197376, Length: 1024}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		// nothing has been created yet
+		{
+			in:  in{disk: types.Disk{Partitions: []types.Partition{root, data}}},
+			out: out{parts: []types.Partition{root, data}, ok: true},
+		},
+		{
+			in:  in{disk: types.Disk{WipeTable: true, Partitions: []types.Partition{root, data}}},
+			out: out{wipe: true, parts: []types.Partition{root, data}, ok: true},
+		},
+		// an interrupted run created some of the partitions
+		{
+			in:  in{disk: types.Disk{Partitions: []types.Partition{root, data, unnumbered}}, existing: []sgdisk.Partition{rootOnDisk, varOnDisk}},
+			out: out{parts: []types.Partition{data}, ok: true},
+		},
+		{
+			in:  in{disk: types.Disk{Partitions: []types.Partition{root, data}}, existing: []sgdisk.Partition{rootOnDisk, dataOnDisk}},
+			out: out{parts: []types.Partition{}, ok: true},
+		},
+		// an existing partition with the same number conflicts
+		{
+			in:  in{disk: types.Disk{Partitions: []types.Partition{{Number: 1, Label: "OTHER"}}}, existing: []sgdisk.Partition{rootOnDisk}},
+			out: out{ok: false},
+		},
+		{
+			in:  in{disk: types.Disk{Partitions: []types.Partition{{Number: 1, Label: "ROOT", Size: 1024}}}, existing: []sgdisk.Partition{rootOnDisk}},
+			out: out{ok: false},
+		},
+		// a wiped table is left alone only if it holds exactly the
+		// configured partitions
+		{
+			in:  in{disk: types.Disk{WipeTable: true, Partitions: []types.Partition{root, data}}, existing: []sgdisk.Partition{rootOnDisk, dataOnDisk}},
+			out: out{parts: nil, ok: true},
+		},
+		{
+			in:  in{disk: types.Disk{WipeTable: true, Partitions: []types.Partition{root}}, existing: []sgdisk.Partition{rootOnDisk, dataOnDisk}},
+			out: out{wipe: true, parts: []types.Partition{root}, ok: true},
+		},
+		{
+			in:  in{disk: types.Disk{WipeTable: true, Partitions: []types.Partition{{Number: 1, Label: "OTHER"}}}, existing: []sgdisk.Partition{rootOnDisk}},
+			out: out{wipe: true, parts: []types.Partition{{Number: 1, Label: "OTHER"}}, ok: true},
+		},
+		{
+			in:  in{disk: types.Disk{WipeTable: true}, existing: []sgdisk.Partition{rootOnDisk}},
+			out: out{wipe: true, parts: nil, ok: true},
+		},
+	}
+
+	for i, test := range tests {
+		wipe, parts, err := pendingPartitions(test.in.disk, test.in.existing)
+		if (err == nil) != test.out.ok {
+			t.Errorf("#%d: bad error: %v", i, err)
+			continue
+		}
+		if !test.out.ok {
+			continue
+		}
+		if wipe != test.out.wipe {
+			t.Errorf("#%d: bad wipe: want %t, got %t", i, test.out.wipe, wipe)
+		}
+		if !reflect.DeepEqual(test.out.parts, parts) {
+			t.Errorf("#%d: bad partitions: want %+v, got %+v", i, test.out.parts, parts)
+		}
+	}
+}
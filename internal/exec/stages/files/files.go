@@ -27,7 +27,6 @@ import (
 	"github.com/coreos/ignition/internal/exec/stages"
 	"github.com/coreos/ignition/internal/exec/util"
 	"github.com/coreos/ignition/internal/log"
-	"github.com/coreos/ignition/internal/resource"
 	internalUtil "github.com/coreos/ignition/internal/util"
 )
 
@@ -45,13 +44,9 @@ func init() {
 
 type creator struct{}
 
-func (creator) Create(logger *log.Logger, root string, f resource.Fetcher) stages.Stage {
+func (creator) Create(u util.Util) stages.Stage {
 	return &stage{
-		Util: util.Util{
-			DestDir: root,
-			Logger:  logger,
-			Fetcher: f,
-		},
+		Util: u,
 	}
 }
 
@@ -123,12 +118,28 @@ func (tmp fileEntry) create(l *log.Logger, u util.Util) error {
 		return fmt.Errorf("failed to resolve file %q", f.Path)
 	}
 
+	return writeFile(u, f, fetchOp)
+}
+
+// prefetchedFileEntry is a file whose contents have already been fetched by
+// util.Prefetch.
+type prefetchedFileEntry struct {
+	file    types.File
+	fetchOp *util.FetchOp
+}
+
+func (e prefetchedFileEntry) create(l *log.Logger, u util.Util) error {
+	return writeFile(u, e.file, e.fetchOp)
+}
+
+// writeFile writes f to disk using the FetchOp prepared for it.
+func writeFile(u util.Util, f types.File, fetchOp *util.FetchOp) error {
 	msg := "writing file %q"
 	if f.Append {
 		msg = "appending to file %q"
 	}
 
-	if err := l.LogOp(
+	if err := u.RunFetchOp(fetchOp,
 		func() error {
 			err := u.DeletePathOnOverwrite(f.Node)
 			if err != nil {
@@ -149,7 +160,7 @@ type dirEntry types.Directory
 func (tmp dirEntry) create(l *log.Logger, u util.Util) error {
 	d := types.Directory(tmp)
 
-	err := l.LogOp(func() error {
+	err := u.RunNodeOp(util.ActionDirectory, d.Node, d.Mode, func() error {
 		path := filepath.Clean(u.JoinPath(string(d.Path)))
 
 		err := u.DeletePathOnOverwrite(d.Node)
@@ -208,7 +219,7 @@ type linkEntry types.Link
 func (tmp linkEntry) create(l *log.Logger, u util.Util) error {
 	s := types.Link(tmp)
 
-	if err := l.LogOp(
+	if err := u.RunNodeOp(util.ActionLink, s.Node, []interface{}{s.Target, s.Hard},
 		func() error {
 			err := u.DeletePathOnOverwrite(s.Node)
 			if err != nil {
@@ -289,7 +300,25 @@ func (s stage) createEntries(fs types.Filesystem, files []filesystemEntry) error
 	defer s.Logger.PopPrefix()
 
 	var mnt string
-	if fs.Path == nil {
+	if fs.Path == nil && fs.Mount.MountPath != nil {
+		// The mount stage has already mounted the filesystem in the root.
+		// If it didn't, entries would silently land on the filesystem
+		// underneath.
+		mnt = s.JoinPath(*fs.Mount.MountPath)
+		if !s.Planning() {
+			if ok, err := util.IsMountPoint(mnt); err != nil {
+				return fmt.Errorf("couldn't check mount of filesystem %q at %q: %v", fs.Name, mnt, err)
+			} else if !ok {
+				return fmt.Errorf("filesystem %q isn't mounted at %q", fs.Name, mnt)
+			}
+		}
+	} else if fs.Path == nil && s.Planning() {
+		// Nothing gets mounted, so entries are planned relative to the root
+		// of the filesystem.
+		dev := string(fs.Mount.Device)
+		s.RunOp(util.ActionMount, dev, nil, "mounting %q", dev)
+		defer s.RunOp(util.ActionUnmount, dev, nil, "unmounting %q", dev)
+	} else if fs.Path == nil {
 		var err error
 		mnt, err = ioutil.TempDir("", "ignition-files")
 		if err != nil {
@@ -315,9 +344,24 @@ func (s stage) createEntries(fs types.Filesystem, files []filesystemEntry) error
 	}
 
 	u := util.Util{
-		DestDir: mnt,
-		Fetcher: s.Util.Fetcher,
-		Logger:  s.Logger,
+		DestDir:      mnt,
+		Fetcher:      s.Util.Fetcher,
+		Plan:         s.Plan,
+		Ledger:       s.Ledger,
+		FetchWorkers: s.FetchWorkers,
+		Logger:       s.Logger,
+	}
+
+	if !s.Planning() {
+		var dir string
+		var err error
+		files, dir, err = prefetchFiles(&u, files)
+		if err != nil {
+			return err
+		}
+		if dir != "" {
+			defer os.RemoveAll(dir)
+		}
 	}
 
 	for _, e := range files {
@@ -329,6 +373,48 @@ func (s stage) createEntries(fs types.Filesystem, files []filesystemEntry) error
 	return nil
 }
 
+// prefetchFiles concurrently fetches the contents of all of the remote files in
+// entries and returns entries with those files replaced by
+// prefetchedFileEntrys. The order of entries is preserved, so directories are
+// still created, and ownership is still set, in the same order as without
+// prefetching. The contents are staged in a temporary directory on the
+// filesystem being written to, which is also returned so the caller can remove
+// it once the entries have been created.
+func prefetchFiles(u *util.Util, entries []filesystemEntry) ([]filesystemEntry, string, error) {
+	ops := []*util.FetchOp{}
+	prefetched := make([]filesystemEntry, len(entries))
+	for i, e := range entries {
+		prefetched[i] = e
+		fe, ok := e.(fileEntry)
+		if !ok {
+			continue
+		}
+		f := types.File(fe)
+		op := u.PrepareFetch(u.Logger, f)
+		if op == nil {
+			return nil, "", fmt.Errorf("failed to resolve file %q", f.Path)
+		}
+		if !op.Prefetchable() {
+			continue
+		}
+		ops = append(ops, op)
+		prefetched[i] = prefetchedFileEntry{file: f, fetchOp: op}
+	}
+	if len(ops) == 0 {
+		return entries, "", nil
+	}
+
+	dir, err := ioutil.TempDir(u.JoinPath("/"), ".ignition-prefetch")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create prefetch directory: %v", err)
+	}
+	if err := u.Prefetch(ops, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, "", err
+	}
+	return prefetched, dir, nil
+}
+
 // createUnits creates the units listed under systemd.units and networkd.units.
 func (s stage) createUnits(config types.Config) error {
 	for _, unit := range config.Systemd.Units {
@@ -337,7 +423,7 @@ func (s stage) createUnits(config types.Config) error {
 		}
 		if unit.Enable {
 			s.Logger.Warning("the enable field has been deprecated in favor of enabled")
-			if err := s.Logger.LogOp(
+			if err := s.RunOp(util.ActionUnit, unit.Name,
 				func() error { return s.EnableUnit(unit) },
 				"enabling unit %q", unit.Name,
 			); err != nil {
@@ -346,14 +432,14 @@ func (s stage) createUnits(config types.Config) error {
 		}
 		if unit.Enabled != nil {
 			if *unit.Enabled {
-				if err := s.Logger.LogOp(
+				if err := s.RunOp(util.ActionUnit, unit.Name,
 					func() error { return s.EnableUnit(unit) },
 					"enabling unit %q", unit.Name,
 				); err != nil {
 					return err
 				}
 			} else {
-				if err := s.Logger.LogOp(
+				if err := s.RunOp(util.ActionUnit, unit.Name,
 					func() error { return s.DisableUnit(unit) },
 					"disabling unit %q", unit.Name,
 				); err != nil {
@@ -362,7 +448,7 @@ func (s stage) createUnits(config types.Config) error {
 			}
 		}
 		if unit.Mask {
-			if err := s.Logger.LogOp(
+			if err := s.RunOp(util.ActionUnit, unit.Name,
 				func() error { return s.MaskUnit(unit) },
 				"masking unit %q", unit.Name,
 			); err != nil {
@@ -393,7 +479,7 @@ func (s stage) writeSystemdUnit(unit types.Unit) error {
 				s.Logger.Crit("error converting systemd dropin: %v", err)
 				return err
 			}
-			if err := s.Logger.LogOp(
+			if err := s.RunFetchOp(f,
 				func() error { return s.PerformFetch(f) },
 				"writing systemd drop-in %q at %q", dropin.Name, f.Path,
 			); err != nil {
@@ -410,7 +496,7 @@ func (s stage) writeSystemdUnit(unit types.Unit) error {
 			s.Logger.Crit("error converting unit: %v", err)
 			return err
 		}
-		if err := s.Logger.LogOp(
+		if err := s.RunFetchOp(f,
 			func() error { return s.PerformFetch(f) },
 			"writing unit %q at %q", unit.Name, f.Path,
 		); err != nil {
@@ -436,7 +522,7 @@ func (s stage) writeNetworkdUnit(unit types.Networkdunit) error {
 				s.Logger.Crit("error converting networkd dropin: %v", err)
 				return err
 			}
-			if err := s.Logger.LogOp(
+			if err := s.RunFetchOp(f,
 				func() error { return s.PerformFetch(f) },
 				"writing networkd drop-in %q at %q", dropin.Name, f.Path,
 			); err != nil {
@@ -452,7 +538,7 @@ func (s stage) writeNetworkdUnit(unit types.Networkdunit) error {
 			s.Logger.Crit("error converting unit: %v", err)
 			return err
 		}
-		if err := s.Logger.LogOp(
+		if err := s.RunFetchOp(f,
 			func() error { return s.PerformFetch(f) },
 			"writing unit %q at %q", unit.Name, f.Path,
 		); err != nil {
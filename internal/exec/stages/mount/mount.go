@@ -0,0 +1,97 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The mount stage is responsible for mounting the filesystems which declare a
+// mountPath, so that the files stage and anything run after it can write to
+// them.
+
+package mount
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/distro"
+	"github.com/coreos/ignition/internal/exec/stages"
+	"github.com/coreos/ignition/internal/exec/util"
+)
+
+const (
+	name = "mount"
+)
+
+func init() {
+	stages.Register(creator{})
+}
+
+type creator struct{}
+
+func (creator) Create(u util.Util) stages.Stage {
+	return &stage{
+		Util: u,
+	}
+}
+
+func (creator) Name() string {
+	return name
+}
+
+type stage struct {
+	util.Util
+}
+
+func (stage) Name() string {
+	return name
+}
+
+func (s stage) Run(config types.Config) bool {
+	for _, m := range util.MountOrder(config.Storage.Filesystems) {
+		if err := s.mountFs(m); err != nil {
+			s.Logger.Crit("failed to mount filesystem: %v", err)
+			return false
+		}
+	}
+
+	return true
+}
+
+// mountFs mounts the device of m at its mountPath, relative to the root.
+func (s stage) mountFs(m types.Mount) error {
+	path := s.JoinPath(*m.MountPath)
+	if !s.Planning() {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("failed to create mountpoint %q: %v", path, err)
+		}
+	}
+
+	args := []string{"-t", m.Format}
+	if len(m.MountOptions) > 0 {
+		opts := []string{}
+		for _, o := range m.MountOptions {
+			opts = append(opts, string(o))
+		}
+		args = append(args, "-o", strings.Join(opts, ","))
+	}
+	args = append(args, m.Device, path)
+
+	cmd := exec.Command(distro.MountCmd(), args...)
+	if _, err := s.RunCmdAs(util.ActionMount, m.Device, cmd, "mounting %q at %q", m.Device, path); err != nil {
+		return fmt.Errorf("failed to mount device %q at %q: %v", m.Device, path, err)
+	}
+
+	return nil
+}
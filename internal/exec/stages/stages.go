@@ -16,9 +16,8 @@ package stages
 
 import (
 	"github.com/coreos/ignition/config/types"
-	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/exec/util"
 	"github.com/coreos/ignition/internal/registry"
-	"github.com/coreos/ignition/internal/resource"
 )
 
 // Stage is responsible for actually executing a stage of the configuration.
@@ -28,9 +27,10 @@ type Stage interface {
 }
 
 // StageCreator is responsible for instantiating a particular stage given a
-// logger and root path under the root partition.
+// util.Util holding the logger, the root path under the root partition, the
+// fetcher, and any other settings (e.g. a plan) for the stage to use.
 type StageCreator interface {
-	Create(logger *log.Logger, root string, f resource.Fetcher) Stage
+	Create(u util.Util) Stage
 	Name() string
 }
 
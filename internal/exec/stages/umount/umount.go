@@ -0,0 +1,72 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The umount stage is responsible for unmounting the filesystems mounted by
+// the mount stage, in the reverse of the order they were mounted in.
+
+package umount
+
+import (
+	"syscall"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/exec/stages"
+	"github.com/coreos/ignition/internal/exec/util"
+)
+
+const (
+	name = "umount"
+)
+
+func init() {
+	stages.Register(creator{})
+}
+
+type creator struct{}
+
+func (creator) Create(u util.Util) stages.Stage {
+	return &stage{
+		Util: u,
+	}
+}
+
+func (creator) Name() string {
+	return name
+}
+
+type stage struct {
+	util.Util
+}
+
+func (stage) Name() string {
+	return name
+}
+
+func (s stage) Run(config types.Config) bool {
+	mounts := util.MountOrder(config.Storage.Filesystems)
+	success := true
+	for i := len(mounts) - 1; i >= 0; i-- {
+		path := s.JoinPath(*mounts[i].MountPath)
+		// Keep going so that as much as possible is unmounted.
+		if err := s.RunOp(util.ActionUnmount, path,
+			func() error { return syscall.Unmount(path, 0) },
+			"unmounting %q", path,
+		); err != nil {
+			s.Logger.Crit("failed to unmount %q: %v", path, err)
+			success = false
+		}
+	}
+
+	return success
+}
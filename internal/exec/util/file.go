@@ -23,7 +23,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/coreos/ignition/config/types"
@@ -46,6 +49,16 @@ type FetchOp struct {
 	Overwrite    *bool
 	Append       bool
 	Node         types.Node
+
+	// Verification is checked for a detached signature of the contents once
+	// they have been fetched (see Fetcher.AssertSigned), which is fetched with
+	// HTTPHeaders like the contents.
+	Verification types.Verification
+	HTTPHeaders  []types.HTTPHeader
+
+	// Prefetched, if set, is the path of a local file that already holds the
+	// fetched and verified contents (see Prefetch).
+	Prefetched string
 }
 
 // newHashedReader returns a new ReadCloser that also writes to the provided hash.
@@ -88,18 +101,27 @@ func (u Util) PrepareFetch(l *log.Logger, f types.File) *FetchOp {
 		}
 	}
 
+	var digest hash.Hash
+	if f.Contents.Verification.Signature != nil {
+		digest = util.NewSignatureHash()
+	}
+
 	return &FetchOp{
-		Path:      f.Path,
-		Hash:      hasher,
-		Node:      f.Node,
-		Url:       *uri,
-		Mode:      f.Mode,
-		Overwrite: f.Overwrite,
-		Append:    f.Append,
+		Path:         f.Path,
+		Hash:         hasher,
+		Node:         f.Node,
+		Url:          *uri,
+		Mode:         f.Mode,
+		Overwrite:    f.Overwrite,
+		Append:       f.Append,
+		Verification: f.Contents.Verification,
+		HTTPHeaders:  f.Contents.HTTPHeaders,
 		FetchOptions: resource.FetchOptions{
+			Headers:     resource.AddHeaders(nil, f.Contents.HTTPHeaders),
 			Hash:        hasher,
 			Compression: f.Contents.Compression,
 			ExpectedSum: expectedSum,
+			Digest:      digest,
 		},
 	}
 }
@@ -182,11 +204,19 @@ func (u Util) PerformFetch(f *FetchOp) error {
 		}
 	}()
 
-	err = u.Fetcher.Fetch(f.Url, tmp, f.FetchOptions)
+	if f.Prefetched != "" {
+		err = copyPrefetched(tmp, f.Prefetched)
+	} else {
+		err = u.Fetcher.Fetch(f.Url, tmp, f.FetchOptions)
+	}
 	if err != nil {
 		u.Crit("Error fetching file %q: %v", f.Path, err)
 		return err
 	}
+	if err = u.assertSigned(f); err != nil {
+		u.Crit("Error verifying file %q: %v", f.Path, err)
+		return err
+	}
 
 	if f.Append {
 		// Make sure that we're appending to a file
@@ -264,6 +294,129 @@ func (u Util) PerformFetch(f *FetchOp) error {
 	return nil
 }
 
+// assertSigned checks the fetched (and decompressed) contents of f against
+// f's signature if it has one, using the digest of the contents computed
+// while they were fetched.
+func (u Util) assertSigned(f *FetchOp) error {
+	if f.Verification.Signature == nil {
+		return nil
+	}
+	if f.FetchOptions.Digest == nil {
+		return fmt.Errorf("no digest of the contents to check the signature against")
+	}
+	return u.Fetcher.AssertSignedDigest(f.Verification, f.HTTPHeaders, f.FetchOptions.Digest.Sum(nil))
+}
+
+// copyPrefetched copies the contents of the prefetched file at src into dest
+// and removes src.
+func copyPrefetched(dest *os.File, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(src)
+	defer in.Close()
+
+	_, err = io.Copy(dest, in)
+	return err
+}
+
+// Prefetchable returns true if f fetches its contents over the network and
+// so benefits from being fetched by Prefetch.
+func (f FetchOp) Prefetchable() bool {
+	switch f.Url.Scheme {
+	case "http", "https", "s3", "tftp":
+		return true
+	default:
+		return false
+	}
+}
+
+// Prefetch fetches the contents of the given FetchOps into temporary files in
+// dir, using up to u.FetchWorkers goroutines which all share u's fetcher. The
+// contents are decompressed and verified as PerformFetch would, and each
+// successfully fetched op has its Prefetched field set so that PerformFetch
+// only needs to copy the local file. If any fetches fail, an error naming
+// every file which failed is returned.
+func (u *Util) Prefetch(ops []*FetchOp, dir string) error {
+	return u.LogOp(func() error {
+		return u.prefetchAll(ops, dir)
+	}, "fetching %d remote file(s)", len(ops))
+}
+
+// prefetchAll implements Prefetch. The workers must not modify the shared
+// logger (e.g. via LogOp), since its prefix stack is not safe for concurrent
+// use.
+func (u *Util) prefetchAll(ops []*FetchOp, dir string) error {
+	for _, op := range ops {
+		if err := u.Fetcher.Prepare(op.Url); err != nil {
+			return err
+		}
+	}
+
+	workers := u.FetchWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	type result struct {
+		op  *FetchOp
+		err error
+	}
+	jobs := make(chan *FetchOp)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(ops); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range jobs {
+				results <- result{op: op, err: u.prefetch(op, dir)}
+			}
+		}()
+	}
+	go func() {
+		for _, op := range ops {
+			jobs <- op
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	failed := []string{}
+	for r := range results {
+		if r.err != nil {
+			u.Crit("Error fetching file %q: %v", r.op.Path, r.err)
+			failed = append(failed, fmt.Sprintf("%q: %v", r.op.Path, r.err))
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("failed to fetch %d file(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// prefetch fetches the contents of op into a new temporary file in dir.
+func (u *Util) prefetch(op *FetchOp, dir string) error {
+	tmp, err := ioutil.TempFile(dir, "prefetch")
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+
+	u.Info("fetching %q for file %q", op.Url.String(), op.Path)
+	if err := u.Fetcher.Fetch(op.Url, tmp, op.FetchOptions); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	op.Prefetched = tmp.Name()
+	return nil
+}
+
 // MkdirForFile helper creates the directory components of path.
 func MkdirForFile(path string) error {
 	return os.MkdirAll(filepath.Dir(path), DefaultDirectoryPermissions)
@@ -0,0 +1,185 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+	"github.com/coreos/ignition/internal/util"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+func TestPrefetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("contents of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ignition-prefetch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logger := log.New(true)
+	u := Util{
+		Fetcher:      resource.Fetcher{Logger: &logger},
+		FetchWorkers: 2,
+		Logger:       &logger,
+	}
+	op := func(path string) *FetchOp {
+		uri, err := url.Parse(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &FetchOp{Path: path, Url: *uri}
+	}
+
+	ops := []*FetchOp{op("/a"), op("/b"), op("/c")}
+	if err := u.Prefetch(ops, dir); err != nil {
+		t.Fatalf("Prefetch: %v", err)
+	}
+	for _, op := range ops {
+		if !op.Prefetchable() {
+			t.Errorf("%q should be prefetchable", op.Url.String())
+		}
+		contents, err := ioutil.ReadFile(op.Prefetched)
+		if err != nil {
+			t.Fatalf("reading prefetched %q: %v", op.Path, err)
+		}
+		if string(contents) != "contents of "+op.Path {
+			t.Errorf("bad contents for %q: %q", op.Path, contents)
+		}
+	}
+
+	ops = []*FetchOp{op("/d"), op("/missing")}
+	err = u.Prefetch(ops, dir)
+	if err == nil || !strings.Contains(err.Error(), `"/missing"`) || strings.Contains(err.Error(), `"/d"`) {
+		t.Errorf("expected an error naming only %q, got %v", "/missing", err)
+	}
+	if ops[1].Prefetched != "" {
+		t.Errorf("failed fetch should not be marked as prefetched")
+	}
+}
+
+func TestSignedFetch(t *testing.T) {
+	contents := []byte(strings.Repeat("signed contents\n", 1024))
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write(contents)
+	w.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(data []byte) *string {
+		sum := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := dataurl.EncodeBytes(sig)
+		return &u
+	}
+
+	dir, err := ioutil.TempDir("", "ignition-signed-fetch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logger := log.New(true)
+	u := Util{
+		DestDir: dir,
+		Fetcher: resource.Fetcher{Logger: &logger, Keyring: util.Keyring{&key.PublicKey}},
+		Logger:  &logger,
+	}
+	tests := []struct {
+		signature *string
+		prefetch  bool
+		ok        bool
+	}{
+		{sign(contents), false, true},
+		{sign(contents), true, true},
+		// the signature is of the decompressed contents
+		{sign(compressed.Bytes()), false, false},
+		{sign(compressed.Bytes()), true, false},
+	}
+
+	for i, test := range tests {
+		f := types.File{
+			Node: types.Node{Filesystem: "root", Path: "/signed"},
+			FileEmbedded1: types.FileEmbedded1{
+				Contents: types.FileContents{
+					Source:       server.URL,
+					Compression:  "gzip",
+					Verification: types.Verification{Signature: test.signature},
+				},
+			},
+		}
+		os.Remove(u.JoinPath(f.Path))
+		op := u.PrepareFetch(&logger, f)
+		if op == nil {
+			t.Fatalf("#%d: PrepareFetch failed", i)
+		}
+		if test.prefetch {
+			if err := u.Prefetch([]*FetchOp{op}, dir); err != nil {
+				t.Fatalf("#%d: Prefetch: %v", i, err)
+			}
+		}
+		err := u.PerformFetch(op)
+		if (err == nil) != test.ok {
+			t.Errorf("#%d: bad error: %v", i, err)
+			continue
+		}
+		written, readErr := ioutil.ReadFile(u.JoinPath(f.Path))
+		if test.ok && !bytes.Equal(written, contents) {
+			t.Errorf("#%d: bad contents: %v", i, readErr)
+		} else if !test.ok && !os.IsNotExist(readErr) {
+			t.Errorf("#%d: badly signed file was written", i)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LedgerPath is where the ledger is kept, relative to the target root. It can
+// only be opened once the target root is mounted, since anything written
+// there before that is hidden by the mount and lost with the initramfs.
+const LedgerPath = "/var/lib/ignition/ledger.json"
+
+// Ledger records the operations which have completed, keyed by a hash of the
+// operation, so that a run which was interrupted can be resumed without
+// repeating operations which aren't idempotent (e.g. appending to a file). It
+// is rewritten and synced after every completed operation.
+type Ledger struct {
+	Completed map[string]LedgerEntry `json:"completed"`
+
+	path  string
+	stage string
+	// seen counts the operations keyed so far in this run by their hash, so
+	// that identical operations (e.g. appending the same line to a file
+	// twice) are told apart by the order they run in.
+	seen map[string]int
+	mu   sync.Mutex
+}
+
+// LedgerEntry describes a completed operation.
+type LedgerEntry struct {
+	Stage       string    `json:"stage"`
+	Description string    `json:"description"`
+	Finished    time.Time `json:"finished"`
+}
+
+// NewLedger returns an empty ledger which is written to path, replacing any
+// ledger already there once the first operation completes.
+func NewLedger(path string) *Ledger {
+	return &Ledger{
+		Completed: map[string]LedgerEntry{},
+		path:      path,
+		seen:      map[string]int{},
+	}
+}
+
+// OpenLedger returns the ledger at path, or an empty one if there is none.
+func OpenLedger(path string) (*Ledger, error) {
+	l := NewLedger(path)
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	} else if err != nil {
+		return l, err
+	}
+	if err := json.Unmarshal(b, l); err != nil {
+		return NewLedger(path), err
+	}
+	if l.Completed == nil {
+		l.Completed = map[string]LedgerEntry{}
+	}
+	return l, nil
+}
+
+// BeginStage sets the name of the stage that subsequent operations belong
+// to. Identical operations in different stages are recorded separately.
+func (l *Ledger) BeginStage(name string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stage = name
+}
+
+// key returns the hash identifying the operation described by parts in the
+// current stage. Each call with the same parts is a further occurrence of the
+// operation, and returns a different key.
+func (l *Ledger) key(parts ...interface{}) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := ledgerHash(append([]interface{}{l.stage}, parts...))
+	n := l.seen[key]
+	l.seen[key]++
+	if n == 0 {
+		return key
+	}
+	return ledgerHash([]interface{}{key, n})
+}
+
+func ledgerHash(parts []interface{}) string {
+	b, err := json.Marshal(parts)
+	if err != nil {
+		// Everything hashed is made of strings, ints and bools.
+		panic(err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// done returns true if the operation identified by key has completed.
+func (l *Ledger) done(key string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.Completed[key]
+	return ok
+}
+
+// record records that the operation identified by key has completed.
+func (l *Ledger) record(key, description string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Completed[key] = LedgerEntry{
+		Stage:       l.stage,
+		Description: description,
+		Finished:    time.Now().UTC(),
+	}
+	return l.write()
+}
+
+// write atomically replaces the ledger file with the current entries, and
+// syncs it so that it survives a power loss.
+func (l *Ledger) write() error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".ledger")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+)
+
+func TestLedger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition-ledger-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, LedgerPath)
+
+	logger := log.New(true)
+	calls := map[string]int{}
+	counter := func(name string) func() error {
+		return func() error {
+			calls[name]++
+			return nil
+		}
+	}
+	fetchOp := func(source string) *FetchOp {
+		u, _ := url.Parse(source)
+		return &FetchOp{
+			Path:   "/etc/motd",
+			Url:    *u,
+			Append: true,
+			Node:   types.Node{Filesystem: "root", Path: "/etc/motd"},
+		}
+	}
+
+	run := func(ledger *Ledger, destDir string) {
+		ledger.BeginStage("files")
+		u := Util{DestDir: destDir, Logger: &logger, Ledger: ledger}
+		u.RunOp(ActionUnit, "foo.service", counter("unit"), "enabling unit %q", "foo.service")
+		u.RunOp(ActionMount, "/dev/vdb", counter("mount"), "mounting %q", "/dev/vdb")
+		u.RunOp(ActionUnit, "bar.service", func() error {
+			calls["failed"]++
+			return errors.New("boom")
+		}, "enabling unit %q", "bar.service")
+		u.RunFetchOp(fetchOp("data:,hello"), counter("append"), "appending to file %q", "/etc/motd")
+
+		// Identical operations are each recorded.
+		u.RunFetchOp(fetchOp("data:,again"), counter("twice"), "appending to file %q", "/etc/motd")
+		u.RunFetchOp(fetchOp("data:,again"), counter("twice"), "appending to file %q", "/etc/motd")
+
+		ledger.BeginStage("disks")
+		u.RunOp(ActionUnit, "foo.service", counter("other stage"), "enabling unit %q", "foo.service")
+	}
+
+	ledger, err := OpenLedger(path)
+	if err != nil {
+		t.Fatalf("OpenLedger: %v", err)
+	}
+	run(ledger, dir)
+
+	// Moving the filesystem doesn't change the identity of a file.
+	ledger, err = OpenLedger(path)
+	if err != nil {
+		t.Fatalf("OpenLedger: %v", err)
+	}
+	run(ledger, filepath.Join(dir, "elsewhere"))
+
+	// A changed file is not skipped.
+	ledger.BeginStage("files")
+	u := Util{DestDir: dir, Logger: &logger, Ledger: ledger}
+	u.RunFetchOp(fetchOp("data:,goodbye"), counter("changed"), "appending to file %q", "/etc/motd")
+
+	// A new ledger reruns everything.
+	run(NewLedger(path), dir)
+
+	expected := map[string]int{
+		"unit":        2,
+		"mount":       3,
+		"failed":      3,
+		"append":      2,
+		"twice":       4,
+		"other stage": 2,
+		"changed":     1,
+	}
+	for name, count := range expected {
+		if calls[name] != count {
+			t.Errorf("%q: expected %d calls, got %d", name, count, calls[name])
+		}
+	}
+}
+
+func TestNilLedger(t *testing.T) {
+	var ledger *Ledger
+	ledger.BeginStage("files")
+	if ledger.done("key") {
+		t.Errorf("nil ledger has completed operations")
+	}
+	if err := ledger.record("key", "nothing"); err != nil {
+		t.Errorf("record on nil ledger: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+// MountOrder returns the mounts of the supplied filesystems which have a
+// mountPath, ordered such that every mount comes after the mounts of its
+// parent directories.
+func MountOrder(filesystems []types.Filesystem) []types.Mount {
+	mounts := []types.Mount{}
+	for _, fs := range filesystems {
+		if fs.Mount == nil || fs.Mount.MountPath == nil {
+			continue
+		}
+		mounts = append(mounts, *fs.Mount)
+	}
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return depth(*mounts[i].MountPath) < depth(*mounts[j].MountPath)
+	})
+	return mounts
+}
+
+func depth(path string) int {
+	path = filepath.Clean(path)
+	if path == "/" {
+		return 0
+	}
+	return strings.Count(path, "/")
+}
+
+// IsMountPoint returns whether path is a mount point, that is, whether it is
+// on a different device than its parent directory, or is the root directory.
+// Bind mounts of a directory on the same device aren't detected.
+func IsMountPoint(path string) (bool, error) {
+	path = filepath.Clean(path)
+	dev, ino, err := devIno(path)
+	if err != nil {
+		return false, err
+	}
+	parentDev, parentIno, err := devIno(filepath.Dir(path))
+	if err != nil {
+		return false, err
+	}
+	return dev != parentDev || ino == parentIno, nil
+}
+
+func devIno(path string) (uint64, uint64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("couldn't get the device of %q", path)
+	}
+	return uint64(st.Dev), uint64(st.Ino), nil
+}
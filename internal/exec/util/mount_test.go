@@ -0,0 +1,80 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestMountOrder(t *testing.T) {
+	strp := func(s string) *string { return &s }
+	mount := func(dev, path string) *types.Mount {
+		return &types.Mount{Device: dev, Format: "ext4", MountPath: strp(path)}
+	}
+
+	in := []types.Filesystem{
+		{Name: "varlog", Mount: mount("/dev/vdc", "/var/log/")},
+		{Name: "root", Path: strp("/sysroot")},
+		{Name: "var", Mount: mount("/dev/vdb", "/var")},
+		{Name: "swap", Mount: &types.Mount{Device: "/dev/vdd", Format: "swap"}},
+		{Name: "srv", Mount: mount("/dev/vde", "/srv")},
+		{Name: "slash", Mount: mount("/dev/vda", "/")},
+	}
+	expected := []string{"/dev/vda", "/dev/vdb", "/dev/vde", "/dev/vdc"}
+
+	got := []string{}
+	for _, m := range MountOrder(in) {
+		got = append(got, m.Device)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("bad mount order: want %v, got %v", expected, got)
+	}
+}
+
+func TestIsMountPoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition-mount-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		path  string
+		mount bool
+		ok    bool
+	}{
+		{path: "/", mount: true, ok: true},
+		{path: "/proc", mount: true, ok: true},
+		{path: dir, mount: false, ok: true},
+		{path: filepath.Join(dir, "missing"), ok: false},
+	}
+
+	for i, test := range tests {
+		mount, err := IsMountPoint(test.path)
+		if (err == nil) != test.ok {
+			t.Errorf("#%d: bad error: %v", i, err)
+			continue
+		}
+		if mount != test.mount {
+			t.Errorf("#%d: bad result for %q: want %t, got %t", i, test.path, test.mount, mount)
+		}
+	}
+}
@@ -116,7 +116,7 @@ func (u Util) EnsureUser(c types.PasswdUser) error {
 
 	args = append(args, c.Name)
 
-	_, err = u.LogCmd(exec.Command(cmd, args...),
+	_, err = u.RunCmd(c.Name, exec.Command(cmd, args...),
 		"creating or modifying user %q", c.Name)
 	return err
 }
@@ -137,6 +137,12 @@ func (u Util) CheckIfUserExists(c types.PasswdUser) (bool, error) {
 		if code == 1 {
 			return false, nil
 		}
+		if u.Planning() {
+			// The target may not be set up yet, so planning should not
+			// fail just because the lookup did.
+			u.Logger.Info("unable to determine if user %q exists, assuming it does not: %v", c.Name, err)
+			return false, nil
+		}
 		u.Logger.Info("error encountered (%+T): %v", err, err)
 		return false, err
 	}
@@ -158,7 +164,7 @@ func (u Util) AuthorizeSSHKeys(c types.PasswdUser) error {
 		return nil
 	}
 
-	return u.LogOp(func() error {
+	return u.RunOp(ActionSSHKeys, c.Name, func() error {
 		usr, err := u.userLookup(c.Name)
 		if err != nil {
 			return fmt.Errorf("unable to lookup user %q", c.Name)
@@ -220,7 +226,7 @@ func (u Util) SetPasswordHash(c types.PasswdUser) error {
 
 	args = append(args, c.Name)
 
-	_, err := u.LogCmd(exec.Command(distro.UsermodCmd(), args...),
+	_, err := u.RunCmd(c.Name, exec.Command(distro.UsermodCmd(), args...),
 		"setting password for %q", c.Name)
 	return err
 }
@@ -246,7 +252,7 @@ func (u Util) CreateGroup(g types.PasswdGroup) error {
 
 	args = append(args, g.Name)
 
-	_, err := u.LogCmd(exec.Command(distro.GroupaddCmd(), args...),
+	_, err := u.RunCmd(g.Name, exec.Command(distro.GroupaddCmd(), args...),
 		"adding group %q", g.Name)
 	return err
 }
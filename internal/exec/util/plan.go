@@ -0,0 +1,200 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+type ActionKind string
+
+const (
+	ActionCommand   ActionKind = "command"
+	ActionFile      ActionKind = "file"
+	ActionDirectory ActionKind = "directory"
+	ActionLink      ActionKind = "link"
+	ActionUnit      ActionKind = "unit"
+	ActionSSHKeys   ActionKind = "ssh-keys"
+	ActionMount     ActionKind = "mount"
+	ActionUnmount   ActionKind = "umount"
+	ActionSettle    ActionKind = "settle"
+	ActionHook      ActionKind = "hook"
+)
+
+// persistent returns true if actions of kind k leave changes on disk, and so
+// are recorded in the ledger and skipped once they have completed.
+func (k ActionKind) persistent() bool {
+	switch k {
+	case ActionMount, ActionUnmount, ActionSettle, ActionHook:
+		return false
+	default:
+		return true
+	}
+}
+
+// Action describes a single change that a stage would make to the system.
+type Action struct {
+	Stage       string     `json:"stage"`
+	Kind        ActionKind `json:"kind"`
+	Target      string     `json:"target,omitempty"`
+	Description string     `json:"description"`
+	Command     []string   `json:"command,omitempty"`
+}
+
+// Plan collects the actions that the stages would take, in the order they
+// would take them, without actually performing any of them.
+type Plan struct {
+	Actions []Action `json:"actions"`
+
+	stage string
+}
+
+// BeginStage sets the name of the stage that subsequently recorded actions
+// belong to.
+func (p *Plan) BeginStage(name string) {
+	p.stage = name
+}
+
+func (p *Plan) add(a Action) {
+	a.Stage = p.stage
+	p.Actions = append(p.Actions, a)
+}
+
+// Planning returns true if actions are being recorded into a plan instead of
+// being performed.
+func (u Util) Planning() bool {
+	return u.Plan != nil
+}
+
+// RunCmd runs the supplied cmd, which acts on target, as a logged operation
+// (see log.Logger.LogTargetedCmd). When planning, the command is recorded in
+// the plan instead of being run. If the ledger shows that the command has
+// already completed, it is skipped.
+func (u Util) RunCmd(target string, cmd *exec.Cmd, format string, a ...interface{}) (int, error) {
+	return u.RunCmdAs(ActionCommand, target, cmd, format, a...)
+}
+
+// RunCmdAs is RunCmd for a command which performs an action of the given
+// kind (e.g. ActionMount).
+func (u Util) RunCmdAs(kind ActionKind, target string, cmd *exec.Cmd, format string, a ...interface{}) (int, error) {
+	description := fmt.Sprintf(format, a...)
+	if u.Planning() {
+		u.Plan.add(Action{
+			Kind:        kind,
+			Target:      target,
+			Description: description,
+			Command:     cmd.Args,
+		})
+		return 0, nil
+	}
+
+	key, skip := u.completed(kind, target, description, cmd.Args)
+	if skip {
+		return 0, nil
+	}
+	code, err := u.LogTargetedCmd(target, cmd, format, a...)
+	if err == nil {
+		u.recordCompleted(key, description)
+	}
+	return code, err
+}
+
+// RunOp calls the supplied op, which acts on target, as a logged operation
+// (see log.Logger.LogTargetedOp). When planning, an action of the given kind
+// is recorded in the plan instead of calling op. If the ledger shows that the
+// op has already completed, it is skipped.
+func (u Util) RunOp(kind ActionKind, target string, op func() error, format string, a ...interface{}) error {
+	return u.runOp(kind, target, target, nil, op, format, a...)
+}
+
+// RunNodeOp is RunOp for an op which creates the supplied node. The op is
+// identified in the ledger by the node's filesystem and path (and by detail,
+// if non-nil) rather than by where the filesystem happens to be mounted.
+func (u Util) RunNodeOp(kind ActionKind, n types.Node, detail interface{}, op func() error, format string, a ...interface{}) error {
+	key := []interface{}{n.Filesystem, n.Path, n.Overwrite, n.User, n.Group, detail}
+	return u.runOp(kind, u.JoinPath(n.Path), "", key, op, format, a...)
+}
+
+// RunFetchOp is RunNodeOp for an op which performs the supplied FetchOp. The
+// FetchOp's source, verification, and resulting mode are part of what
+// identifies the op in the ledger, so that a changed file is not skipped.
+func (u Util) RunFetchOp(f *FetchOp, op func() error, format string, a ...interface{}) error {
+	var sum string
+	if f.FetchOptions.ExpectedSum != nil {
+		sum = hex.EncodeToString(f.FetchOptions.ExpectedSum)
+	}
+	detail := []interface{}{
+		f.Url.String(), sum, f.FetchOptions.Compression, f.Mode, f.Overwrite, f.Append,
+	}
+	// FetchOps for units don't have a node, just a path.
+	n := f.Node
+	n.Path = f.Path
+	return u.RunNodeOp(ActionFile, n, detail, op, format, a...)
+}
+
+// runOp implements RunOp. The op is identified in the ledger by keyTarget and
+// detail instead of by target.
+func (u Util) runOp(kind ActionKind, target, keyTarget string, detail interface{}, op func() error, format string, a ...interface{}) error {
+	description := fmt.Sprintf(format, a...)
+	if u.Planning() {
+		u.Plan.add(Action{
+			Kind:        kind,
+			Target:      target,
+			Description: description,
+		})
+		return nil
+	}
+
+	key, skip := u.completed(kind, keyTarget, description, detail)
+	if skip {
+		return nil
+	}
+	err := u.LogTargetedOp(target, op, format, a...)
+	if err == nil {
+		u.recordCompleted(key, description)
+	}
+	return err
+}
+
+// completed returns the ledger key for an action, and whether the ledger
+// shows that it has already completed, in which case the skip is logged. The
+// key is empty if there is no ledger or the action isn't persistent.
+func (u Util) completed(kind ActionKind, target, description string, detail interface{}) (string, bool) {
+	if u.Ledger == nil || !kind.persistent() {
+		return "", false
+	}
+	key := u.Ledger.key(kind, target, description, detail)
+	if u.Ledger.done(key) {
+		u.Info("skipping %s: already completed", description)
+		return key, true
+	}
+	return key, false
+}
+
+// recordCompleted records the action identified by key in the ledger.
+// Failing to do so is logged but isn't fatal, since the only consequence is
+// that the action is repeated on a re-run.
+func (u Util) recordCompleted(key, description string) {
+	if key == "" {
+		return
+	}
+	if err := u.Ledger.record(key, description); err != nil {
+		u.Err("failed to update ledger: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/internal/log"
+)
+
+func TestPlan(t *testing.T) {
+	logger := log.New(true)
+	plan := &Plan{}
+	u := Util{DestDir: "/sysroot", Logger: &logger, Plan: plan}
+
+	plan.BeginStage("disks")
+	if _, err := u.RunCmd("/dev/md/foo", exec.Command("/usr/sbin/mdadm", "--create", "/dev/md/foo"), "creating %q", "foo"); err != nil {
+		t.Fatalf("RunCmd: %v", err)
+	}
+	plan.BeginStage("files")
+	called := false
+	if err := u.RunOp(ActionFile, u.JoinPath("/etc/hostname"), func() error {
+		called = true
+		return errors.New("should not be called")
+	}, "writing file %q", "/etc/hostname"); err != nil {
+		t.Fatalf("RunOp: %v", err)
+	}
+	if called {
+		t.Errorf("op was called while planning")
+	}
+
+	expected := []Action{
+		{
+			Stage:       "disks",
+			Kind:        ActionCommand,
+			Target:      "/dev/md/foo",
+			Description: `creating "foo"`,
+			Command:     []string{"/usr/sbin/mdadm", "--create", "/dev/md/foo"},
+		},
+		{
+			Stage:       "files",
+			Kind:        ActionFile,
+			Target:      "/sysroot/etc/hostname",
+			Description: `writing file "/etc/hostname"`,
+		},
+	}
+	if !reflect.DeepEqual(expected, plan.Actions) {
+		t.Errorf("bad plan: want %+v, got %+v", expected, plan.Actions)
+	}
+}
+
+func TestRunOpWithoutPlan(t *testing.T) {
+	logger := log.New(true)
+	u := Util{Logger: &logger}
+
+	called := false
+	if err := u.RunOp(ActionFile, "/foo", func() error {
+		called = true
+		return nil
+	}, "writing file %q", "/foo"); err != nil {
+		t.Fatalf("RunOp: %v", err)
+	}
+	if !called {
+		t.Errorf("op was not called")
+	}
+}
@@ -25,6 +25,11 @@ import (
 type Util struct {
 	DestDir string // directory prefix to use in applying fs paths.
 	Fetcher resource.Fetcher
+	Plan    *Plan   // if non-nil, actions are recorded here instead of performed.
+	Ledger  *Ledger // if non-nil, completed actions are recorded here and skipped.
+
+	FetchWorkers int  // maximum number of remote files to prefetch at once.
+	DevicesReady bool // if true, devices are known to exist and aren't waited on.
 	*log.Logger
 }
 
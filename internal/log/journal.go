@@ -0,0 +1,152 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Journal is a machine-readable record of the configs Ignition used and of
+// every operation run through LogOp and LogCmd. It is rewritten after every
+// change, so it is complete up to the point of a failure, and it is shared
+// by every stage that is run with the same path.
+type Journal struct {
+	Configs    []ConfigRecord `json:"configs"`
+	Operations []OpRecord     `json:"operations"`
+
+	path   string
+	stage  string
+	source string
+	mu     sync.Mutex
+}
+
+// ConfigRecord describes where a config that Ignition parsed came from.
+type ConfigRecord struct {
+	Source string `json:"source"`
+	SHA512 string `json:"sha512"`
+}
+
+// OpRecord describes the outcome of a single operation.
+type OpRecord struct {
+	Stage      string    `json:"stage,omitempty"`
+	Operation  string    `json:"operation"`
+	Target     string    `json:"target,omitempty"`
+	Started    time.Time `json:"started"`
+	Finished   time.Time `json:"finished"`
+	Success    bool      `json:"success"`
+	ExitStatus *int      `json:"exitStatus,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// OpenJournal returns a journal which is written to path. If path already
+// holds a journal (e.g. from an earlier stage), new records are added to it.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	} else if err != nil {
+		return j, err
+	}
+	if err := json.Unmarshal(b, j); err != nil {
+		return &Journal{path: path}, err
+	}
+	return j, nil
+}
+
+// BeginStage sets the name of the stage that subsequent operations belong
+// to.
+func (j *Journal) BeginStage(name string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stage = name
+}
+
+// SetConfigSource sets the source (e.g. "cmdline") attributed to configs
+// recorded by subsequent calls to RecordConfig.
+func (j *Journal) SetConfigSource(source string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.source = source
+}
+
+// RecordConfig records the hash of a raw config fetched from the current
+// config source.
+func (j *Journal) RecordConfig(rawConfig []byte) error {
+	if j == nil {
+		return nil
+	}
+	sum := sha512.Sum512(rawConfig)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Configs = append(j.Configs, ConfigRecord{
+		Source: j.source,
+		SHA512: hex.EncodeToString(sum[:]),
+	})
+	return j.write()
+}
+
+func (j *Journal) recordOp(rec OpRecord) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	rec.Stage = j.stage
+	j.Operations = append(j.Operations, rec)
+	return j.write()
+}
+
+// write atomically replaces the journal file with the current records.
+func (j *Journal) write() error {
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(j.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".journal")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), j.path)
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignition-journal-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ignition", "result.json")
+
+	// The first "stage" records a config and a successful op.
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	logger := New(true)
+	logger.SetJournal(j)
+	j.BeginStage("disks")
+	j.SetConfigSource("cmdline")
+	if err := j.RecordConfig([]byte("{}")); err != nil {
+		t.Fatalf("RecordConfig: %v", err)
+	}
+	if err := logger.LogTargetedOp("/dev/sda", func() error { return nil }, "partitioning %q", "/dev/sda"); err != nil {
+		t.Fatalf("LogTargetedOp: %v", err)
+	}
+
+	// The second "stage" reopens the journal and records failures.
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	logger.SetJournal(j)
+	j.BeginStage("files")
+	logger.LogOp(func() error { return errors.New("boom") }, "writing file %q", "/foo")
+	logger.LogTargetedCmd("core", exec.Command("false"), "creating user %q", "core")
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+
+	if len(j.Configs) != 1 || j.Configs[0].Source != "cmdline" ||
+		j.Configs[0].SHA512 != "27c74670adb75075fad058d5ceaf7b20c4e7786c83bae8a32f626f9782af34c9a33c2046ef60fd2a7878d378e29fec851806bbd9a67878f3a9f1cda4830763fd" {
+		t.Errorf("bad configs: %+v", j.Configs)
+	}
+	if len(j.Operations) != 3 {
+		t.Fatalf("expected 3 operations, got %+v", j.Operations)
+	}
+
+	op := j.Operations[0]
+	if op.Stage != "disks" || op.Target != "/dev/sda" || op.Operation != `partitioning "/dev/sda"` ||
+		!op.Success || op.Error != "" || op.ExitStatus != nil || op.Finished.Before(op.Started) {
+		t.Errorf("bad first operation: %+v", op)
+	}
+	op = j.Operations[1]
+	if op.Stage != "files" || op.Success || op.Error != "boom" {
+		t.Errorf("bad second operation: %+v", op)
+	}
+	op = j.Operations[2]
+	if op.Target != "core" || op.Success || op.ExitStatus == nil || *op.ExitStatus != 1 {
+		t.Errorf("bad third operation: %+v", op)
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	logger := New(true)
+	logger.Journal().BeginStage("files")
+	logger.Journal().SetConfigSource("cmdline")
+	if err := logger.Journal().RecordConfig([]byte("{}")); err != nil {
+		t.Errorf("RecordConfig on nil journal: %v", err)
+	}
+	if err := logger.LogOp(func() error { return nil }, "nothing"); err != nil {
+		t.Errorf("LogOp without journal: %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"os/exec"
 	"strings"
 	"syscall"
+	"time"
 )
 
 type LoggerOps interface {
@@ -40,6 +41,7 @@ type Logger struct {
 	ops           LoggerOps
 	prefixStack   []string
 	opSequenceNum int
+	journal       *Journal
 }
 
 // New creates a new logger.
@@ -60,6 +62,22 @@ func New(logToStdout bool) Logger {
 	return logger
 }
 
+// NewWithOps creates a new logger which logs using the supplied ops.
+func NewWithOps(ops LoggerOps) Logger {
+	return Logger{ops: ops}
+}
+
+// SetJournal sets the journal that LogOp and LogCmd record operations into.
+func (l *Logger) SetJournal(j *Journal) {
+	l.journal = j
+}
+
+// Journal returns the logger's journal, or nil if it has none. All of the
+// Journal methods may be called on the nil journal.
+func (l Logger) Journal() *Journal {
+	return l.journal
+}
+
 // Close closes the logger.
 func (l Logger) Close() {
 	l.ops.Close()
@@ -138,6 +156,11 @@ func quotedCmd(cmd *exec.Cmd) string {
 // LogCmd runs and logs the supplied cmd as an operation with distinct start/finish/fail log messages uniformly combined with the supplied format string.
 // The exact command path and arguments being executed are also logged for debugging assistance.
 func (l *Logger) LogCmd(cmd *exec.Cmd, format string, a ...interface{}) (int, error) {
+	return l.LogTargetedCmd("", cmd, format, a...)
+}
+
+// LogTargetedCmd is LogCmd for a command acting on target (e.g. a device, path, unit, or user), which is included in the journal record.
+func (l *Logger) LogTargetedCmd(target string, cmd *exec.Cmd, format string, a ...interface{}) (int, error) {
 	code := -1
 	f := func() error {
 		cmdLine := quotedCmd(cmd)
@@ -155,18 +178,52 @@ func (l *Logger) LogCmd(cmd *exec.Cmd, format string, a ...interface{}) (int, er
 		}
 		return nil
 	}
-	err := l.LogOp(f, format, a...)
+	err := l.logOp(target, &code, f, format, a...)
 	return code, err
 }
 
 // LogOp calls and logs the supplied function as an operation with distinct start/finish/fail log messages uniformly combined with the supplied format string.
 func (l *Logger) LogOp(op func() error, format string, a ...interface{}) error {
+	return l.logOp("", nil, op, format, a...)
+}
+
+// LogTargetedOp is LogOp for an operation acting on target (e.g. a device, path, unit, or user), which is included in the journal record.
+func (l *Logger) LogTargetedOp(target string, op func() error, format string, a ...interface{}) error {
+	return l.logOp(target, nil, op, format, a...)
+}
+
+// logOp implements LogOp, additionally recording the operation in the journal. If code is non-nil, it is read after op returns and recorded as the exit status.
+func (l *Logger) logOp(target string, code *int, op func() error, format string, a ...interface{}) error {
 	l.opSequenceNum++
 	l.PushPrefix("op(%x)", l.opSequenceNum)
 	defer l.PopPrefix()
 
+	rec := OpRecord{
+		Operation: fmt.Sprintf(format, a...),
+		Target:    target,
+		Started:   time.Now().UTC(),
+	}
 	l.logStart(format, a...)
-	if err := op(); err != nil {
+	err := op()
+	rec.Finished = time.Now().UTC()
+	rec.Success = err == nil
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if code != nil {
+		status := *code
+		if err == nil {
+			status = 0
+		}
+		if status >= 0 {
+			rec.ExitStatus = &status
+		}
+	}
+	if jerr := l.journal.recordOp(rec); jerr != nil {
+		l.Err("failed to write journal: %v", jerr)
+	}
+
+	if err != nil {
 		l.logFail("%s: %v", fmt.Sprintf(format, a...), err)
 		return err
 	}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -24,8 +25,12 @@ import (
 	"github.com/coreos/ignition/internal/exec/stages"
 	_ "github.com/coreos/ignition/internal/exec/stages/disks"
 	_ "github.com/coreos/ignition/internal/exec/stages/files"
+	_ "github.com/coreos/ignition/internal/exec/stages/mount"
+	_ "github.com/coreos/ignition/internal/exec/stages/umount"
+	"github.com/coreos/ignition/internal/exec/util"
 	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/oem"
+	"github.com/coreos/ignition/internal/providers/cmdline"
 	"github.com/coreos/ignition/internal/version"
 )
 
@@ -34,7 +39,13 @@ func main() {
 		clearCache   bool
 		configCache  string
 		fetchTimeout time.Duration
+		fetchWorkers int
+		forceRerun   bool
+		journal      string
+		mergeConfigs bool
+		strict       bool
 		oem          oem.Name
+		plan         bool
 		root         string
 		stage        stages.Name
 		version      bool
@@ -44,8 +55,14 @@ func main() {
 	flag.BoolVar(&flags.clearCache, "clear-cache", false, "clear any cached config")
 	flag.StringVar(&flags.configCache, "config-cache", "/run/ignition.json", "where to cache the config")
 	flag.DurationVar(&flags.fetchTimeout, "fetch-timeout", exec.DefaultFetchTimeout, "initial duration for which to wait for config")
+	flag.IntVar(&flags.fetchWorkers, "fetch-workers", exec.DefaultFetchWorkers, "maximum number of remote files to fetch at once")
+	flag.BoolVar(&flags.forceRerun, "force-rerun", false, "rerun every operation, including those the ledger shows as completed")
+	flag.StringVar(&flags.journal, "journal", "/run/ignition/result.json", "where to write the machine-readable result of each operation, or \"\" to disable")
+	flag.BoolVar(&flags.mergeConfigs, "merge-configs", false, "merge entries with the same path or name when layering configs instead of appending them")
 	flag.Var(&flags.oem, "oem", fmt.Sprintf("current oem. %v", oem.Names()))
+	flag.BoolVar(&flags.plan, "plan", false, "print the actions the stage(s) would take as JSON instead of taking them")
 	flag.StringVar(&flags.root, "root", "/", "root of the filesystem")
+	flag.BoolVar(&flags.strict, "strict", false, "reject configs containing keys which aren't part of the spec (also set by the \"ignition.config.strict\" kernel option)")
 	flag.Var(&flags.stage, "stage", fmt.Sprintf("execution stage. %v", stages.Names()))
 	flag.BoolVar(&flags.version, "version", false, "print the version and exit")
 	flag.BoolVar(&flags.logToStdout, "log-to-stdout", false, "log to stdout instead of the system log when set")
@@ -62,7 +79,7 @@ func main() {
 		os.Exit(2)
 	}
 
-	if flags.stage == "" {
+	if flags.stage == "" && !flags.plan {
 		fmt.Fprint(os.Stderr, "'--stage' must be provided\n")
 		os.Exit(2)
 	}
@@ -72,6 +89,14 @@ func main() {
 
 	logger.Info(version.String)
 
+	if flags.journal != "" && !flags.plan {
+		journal, err := log.OpenJournal(flags.journal)
+		if err != nil {
+			logger.Err("unable to read existing journal, starting a new one: %v", err)
+		}
+		logger.SetJournal(journal)
+	}
+
 	if flags.clearCache {
 		if err := os.Remove(flags.configCache); err != nil {
 			logger.Err("unable to clear cache: %v", err)
@@ -80,14 +105,47 @@ func main() {
 
 	oemConfig := oem.MustGet(flags.oem.String())
 	engine := exec.Engine{
-		Root:         flags.root,
-		FetchTimeout: flags.fetchTimeout,
-		Logger:       &logger,
-		ConfigCache:  flags.configCache,
-		OEMConfig:    oemConfig,
+		Root:          flags.root,
+		FetchTimeout:  flags.fetchTimeout,
+		FetchWorkers:  flags.fetchWorkers,
+		MergeConfigs:  flags.mergeConfigs,
+		StrictConfigs: flags.strict || cmdline.StrictConfigs(&logger),
+		Logger:        &logger,
+		ConfigCache:   flags.configCache,
+		OEMConfig:     oemConfig,
+		LedgerPath:    util.LedgerPath,
+		ForceRerun:    flags.forceRerun,
+	}
+
+	if flags.plan {
+		runPlan(engine, flags.stage)
+		return
 	}
 
 	if !engine.Run(flags.stage.String()) {
 		os.Exit(1)
 	}
 }
+
+// runPlan plans the given stage, or the disks, mount, files and umount stages
+// if none was given, and prints the resulting plan to stdout.
+func runPlan(engine exec.Engine, stage stages.Name) {
+	names := []string{"disks", "mount", "files", "umount"}
+	if stage != "" {
+		names = []string{stage.String()}
+	}
+
+	engine.Plan = &util.Plan{}
+	for _, name := range names {
+		if !engine.Run(name) {
+			os.Exit(1)
+		}
+	}
+
+	b, err := json.MarshalIndent(engine.Plan, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal plan: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s\n", b)
+}
@@ -160,6 +160,17 @@ func init() {
 	})
 }
 
+// Register registers the config for an OEM named name, which fetches its
+// config with fetch and, if newFetcher is non-nil, creates its fetcher with
+// newFetcher.
+func Register(name string, fetch providers.FuncFetchConfig, newFetcher providers.FuncNewFetcher) {
+	configs.Register(Config{
+		name:       name,
+		fetch:      fetch,
+		newFetcher: newFetcher,
+	})
+}
+
 func Get(name string) (config Config, ok bool) {
 	config, ok = configs.Get(name).(Config)
 	return
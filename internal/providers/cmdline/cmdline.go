@@ -12,14 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// The cmdline provider fetches a remote configuration from the URL specified
-// in the kernel boot option "coreos.config.url".
+// The cmdline provider fetches a remote configuration from the URLs specified
+// in the kernel boot options "coreos.config.url" and "ignition.config.url".
+// Either option may be repeated or given a "|"-separated list of URLs, which
+// are tried in order until one of them can be fetched. A "|" which is part of
+// a URL must be percent-encoded as "%7C". The kernel boot option
+// "ignition.config.strict" causes configs containing keys which aren't part of
+// the spec to be rejected.
 
 package cmdline
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/coreos/ignition/config/types"
@@ -32,21 +39,59 @@ import (
 )
 
 const (
-	cmdlineUrlFlag = "coreos.config.url"
+	cmdlineUrlFlag      = "coreos.config.url"
+	cmdlineUrlFlagAlias = "ignition.config.url"
+
+	// cmdlineUrlSeparator separates alternate URLs given in a single option.
+	// RFC 3986 doesn't allow it unescaped in a URL, unlike a comma, so URLs
+	// containing one must percent-encode it as "%7C", which is passed on to
+	// the server as is.
+	cmdlineUrlSeparator = "|"
+
+	cmdlineStrictFlag = "ignition.config.strict"
+
+	// proxies to use before a config provides its own, and the hosts which
+	// shouldn't be reached through them, separated by commas
+	cmdlineHTTPProxyFlag  = "ignition.proxy.http"
+	cmdlineHTTPSProxyFlag = "ignition.proxy.https"
+	cmdlineNoProxyFlag    = "ignition.proxy.noproxy"
+
+	// sigSuffix is appended to the path of a config url to get the url of
+	// its detached signature.
+	sigSuffix = ".sig"
 )
 
 func FetchConfig(f resource.Fetcher) (types.Config, report.Report, error) {
-	url, err := readCmdline(f.Logger)
+	urls, err := readCmdline(f.Logger)
 	if err != nil {
 		return types.Config{}, report.Report{}, err
 	}
 
-	if url == nil {
+	if len(urls) == 0 {
 		return types.Config{}, report.Report{}, providers.ErrNoProvider
 	}
 
-	data, err := f.FetchToBuffer(*url, resource.FetchOptions{
+	return fetchConfig(f, urls)
+}
+
+// fetchConfig fetches the config from the first of urls which serves one with
+// a valid signature, if signatures are required, so that a bad signature on
+// one server doesn't prevent the rest from being tried.
+func fetchConfig(f resource.Fetcher, urls []url.URL) (types.Config, report.Report, error) {
+	data, _, err := f.FetchToBufferFromAny(urls, resource.FetchOptions{
 		Headers: resource.ConfigHeaders,
+	}, func(u url.URL, data []byte) error {
+		// A config given as a data url is as trustworthy as the cmdline
+		// itself. Others must have a detached signature alongside them.
+		if len(f.Keyring) == 0 || u.Scheme == "data" {
+			return nil
+		}
+		sigURL := signatureURL(u)
+		f.Logger.Info("checking signature %s", sigURL.String())
+		if err := f.AssertSignedBy(sigURL, nil, data); err != nil {
+			return fmt.Errorf("config %s: %v", u.String(), err)
+		}
+		return nil
 	})
 	if err != nil {
 		return types.Config{}, report.Report{}, err
@@ -55,40 +100,116 @@ func FetchConfig(f resource.Fetcher) (types.Config, report.Report, error) {
 	return util.ParseConfig(f.Logger, data)
 }
 
-func readCmdline(logger *log.Logger) (*url.URL, error) {
+// StrictConfigs returns whether the kernel boot options request that configs
+// containing keys which aren't part of the spec be rejected.
+func StrictConfigs(logger *log.Logger) bool {
+	args, err := ioutil.ReadFile(distro.KernelCmdlinePath())
+	if err != nil {
+		logger.Err("couldn't read cmdline: %v", err)
+		return false
+	}
+	return parseStrict(args)
+}
+
+// Proxy returns the proxy settings given on the cmdline.
+func Proxy(logger *log.Logger) types.Proxy {
+	args, err := ioutil.ReadFile(distro.KernelCmdlinePath())
+	if err != nil {
+		logger.Err("couldn't read cmdline: %v", err)
+		return types.Proxy{}
+	}
+	return parseProxy(args)
+}
+
+func readCmdline(logger *log.Logger) ([]url.URL, error) {
 	args, err := ioutil.ReadFile(distro.KernelCmdlinePath())
 	if err != nil {
 		logger.Err("couldn't read cmdline: %v", err)
 		return nil, err
 	}
 
-	rawUrl := parseCmdline(args)
-	logger.Debug("parsed url from cmdline: %q", rawUrl)
-	if rawUrl == "" {
+	rawUrls := parseCmdline(args)
+	logger.Debug("parsed urls from cmdline: %q", rawUrls)
+	if len(rawUrls) == 0 {
 		logger.Info("no config URL provided")
 		return nil, nil
 	}
 
-	url, err := url.Parse(rawUrl)
-	if err != nil {
-		logger.Err("failed to parse url: %v", err)
-		return nil, err
+	urls := make([]url.URL, 0, len(rawUrls))
+	for _, rawUrl := range rawUrls {
+		url, err := url.Parse(rawUrl)
+		if err != nil {
+			logger.Err("failed to parse url: %v", err)
+			return nil, err
+		}
+		urls = append(urls, *url)
 	}
 
-	return url, err
+	return urls, nil
+}
+
+// signatureURL returns the url of the detached signature of the config at u,
+// which is the url with ".sig" appended to its path.
+func signatureURL(u url.URL) url.URL {
+	u.Path += sigSuffix
+	if u.Opaque != "" {
+		u.Opaque += sigSuffix
+	}
+	return u
 }
 
-func parseCmdline(cmdline []byte) (url string) {
+func parseCmdline(cmdline []byte) (urls []string) {
 	for _, arg := range strings.Split(string(cmdline), " ") {
 		parts := strings.SplitN(strings.TrimSpace(arg), "=", 2)
 		key := parts[0]
 
-		if key != cmdlineUrlFlag {
+		if key != cmdlineUrlFlag && key != cmdlineUrlFlagAlias {
 			continue
 		}
 
 		if len(parts) == 2 {
-			url = parts[1]
+			for _, url := range strings.Split(parts[1], cmdlineUrlSeparator) {
+				if url != "" {
+					urls = append(urls, url)
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// parseStrict returns whether cmdline contains the strict flag, either bare or
+// set to a true value such as "1".
+func parseStrict(cmdline []byte) (strict bool) {
+	for _, arg := range strings.Split(string(cmdline), " ") {
+		parts := strings.SplitN(strings.TrimSpace(arg), "=", 2)
+		if parts[0] != cmdlineStrictFlag {
+			continue
+		}
+		strict = true
+		if len(parts) == 2 {
+			strict, _ = strconv.ParseBool(parts[1])
+		}
+	}
+
+	return
+}
+
+func parseProxy(cmdline []byte) (proxy types.Proxy) {
+	for _, arg := range strings.Split(string(cmdline), " ") {
+		parts := strings.SplitN(strings.TrimSpace(arg), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		value := parts[1]
+		switch parts[0] {
+		case cmdlineHTTPProxyFlag:
+			proxy.HTTPProxy = &value
+		case cmdlineHTTPSProxyFlag:
+			proxy.HTTPSProxy = &value
+		case cmdlineNoProxyFlag:
+			proxy.NoProxy = resource.SplitNoProxy(value)
 		}
 	}
 
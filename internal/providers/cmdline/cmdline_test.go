@@ -0,0 +1,262 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdline
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+	"github.com/coreos/ignition/internal/util"
+)
+
+func TestParseCmdline(t *testing.T) {
+	type in struct {
+		cmdline string
+	}
+	type out struct {
+		urls []string
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{cmdline: "quiet root=/dev/sda9"},
+			out: out{urls: nil},
+		},
+		{
+			in:  in{cmdline: "coreos.config.url=http://a/config.ign"},
+			out: out{urls: []string{"http://a/config.ign"}},
+		},
+		{
+			in:  in{cmdline: "ignition.config.url=http://a/config.ign\n"},
+			out: out{urls: []string{"http://a/config.ign"}},
+		},
+		{
+			in:  in{cmdline: "coreos.config.url=http://a/config.ign quiet ignition.config.url=http://b/config.ign"},
+			out: out{urls: []string{"http://a/config.ign", "http://b/config.ign"}},
+		},
+		{
+			in:  in{cmdline: "coreos.config.url=http://a/config.ign|http://b/config.ign||data:,a,b"},
+			out: out{urls: []string{"http://a/config.ign", "http://b/config.ign", "data:,a,b"}},
+		},
+		{
+			in:  in{cmdline: "ignition.config.url=http://a/config.ign?x=a%7Cb|http://b/config.ign"},
+			out: out{urls: []string{"http://a/config.ign?x=a%7Cb", "http://b/config.ign"}},
+		},
+		{
+			in:  in{cmdline: "coreos.config.url coreos.config.url="},
+			out: out{urls: nil},
+		},
+	}
+
+	for i, test := range tests {
+		urls := parseCmdline([]byte(test.in.cmdline))
+		if !reflect.DeepEqual(test.out.urls, urls) {
+			t.Errorf("#%d: bad urls: want %q, got %q", i, test.out.urls, urls)
+		}
+	}
+}
+
+func TestEncodedSeparator(t *testing.T) {
+	urls := parseCmdline([]byte("coreos.config.url=https://a/config.ign?token=a%7Cb"))
+	if len(urls) != 1 {
+		t.Fatalf("expected one url, got %q", urls)
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token := u.Query().Get("token"); token != "a|b" {
+		t.Errorf("bad token: want %q, got %q", "a|b", token)
+	}
+	if u.String() != urls[0] {
+		t.Errorf("encoded url changed: want %q, got %q", urls[0], u.String())
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	type in struct {
+		cmdline string
+	}
+	type out struct {
+		strict bool
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{cmdline: "quiet root=/dev/sda9"},
+			out: out{strict: false},
+		},
+		{
+			in:  in{cmdline: "quiet ignition.config.strict\n"},
+			out: out{strict: true},
+		},
+		{
+			in:  in{cmdline: "ignition.config.strict=1"},
+			out: out{strict: true},
+		},
+		{
+			in:  in{cmdline: "ignition.config.strict ignition.config.strict=0"},
+			out: out{strict: false},
+		},
+	}
+
+	for i, test := range tests {
+		strict := parseStrict([]byte(test.in.cmdline))
+		if test.out.strict != strict {
+			t.Errorf("#%d: bad strict: want %t, got %t", i, test.out.strict, strict)
+		}
+	}
+}
+
+func TestSignatureURL(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{
+			in:  "http://a/config.ign",
+			out: "http://a/config.ign.sig",
+		},
+		{
+			in:  "https://a/config.ign?token=abc",
+			out: "https://a/config.ign.sig?token=abc",
+		},
+		{
+			in:  "s3://bucket/path/config.ign",
+			out: "s3://bucket/path/config.ign.sig",
+		},
+	}
+
+	for i, test := range tests {
+		u, err := url.Parse(test.in)
+		if err != nil {
+			t.Fatalf("#%d: bad url: %v", i, err)
+		}
+		sigURL := signatureURL(*u)
+		if out := sigURL.String(); test.out != out {
+			t.Errorf("#%d: bad signature url: want %q, got %q", i, test.out, out)
+		}
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	httpProxy := "http://proxy:3128"
+	tests := []struct {
+		in  string
+		out types.Proxy
+	}{
+		{
+			in:  "quiet root=/dev/sda9",
+			out: types.Proxy{},
+		},
+		{
+			in: "ignition.proxy.http=http://proxy:3128 ignition.proxy.https= ignition.proxy.noproxy=example.com,10.0.0.0/8\n",
+			out: types.Proxy{
+				HTTPProxy: &httpProxy,
+				NoProxy:   []types.NoProxyItem{"example.com", "10.0.0.0/8"},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		if proxy := parseProxy([]byte(test.in)); !reflect.DeepEqual(test.out, proxy) {
+			t.Errorf("#%d: bad proxy: want %+v, got %+v", i, test.out, proxy)
+		}
+	}
+}
+
+func TestFetchConfigSignatureFallback(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(data []byte) []byte {
+		sum := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	bad := []byte(`{"ignition": {"version": "2.1.0"}, "passwd": {"users": [{"name": "bad"}]}}`)
+	good := []byte(`{"ignition": {"version": "2.1.0"}, "passwd": {"users": [{"name": "good"}]}}`)
+	files := map[string][]byte{
+		// signed, but by the wrong config
+		"/bad-sig/config.ign":     bad,
+		"/bad-sig/config.ign.sig": sign(good),
+		// not signed at all
+		"/no-sig/config.ign":   bad,
+		"/good/config.ign":     good,
+		"/good/config.ign.sig": sign(good),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+	parse := func(paths ...string) []url.URL {
+		urls := []url.URL{}
+		for _, p := range paths {
+			u, err := url.Parse(server.URL + p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			urls = append(urls, *u)
+		}
+		return urls
+	}
+
+	logger := log.New(true)
+	f := resource.Fetcher{Logger: &logger, Keyring: util.Keyring{&key.PublicKey}}
+
+	cfg, _, err := fetchConfig(f, parse("/bad-sig/config.ign", "/no-sig/config.ign", "/good/config.ign"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Passwd.Users) != 1 || cfg.Passwd.Users[0].Name != "good" {
+		t.Errorf("expected the config with a good signature, got %+v", cfg.Passwd)
+	}
+
+	if _, _, err := fetchConfig(f, parse("/bad-sig/config.ign", "/no-sig/config.ign")); err == nil {
+		t.Errorf("expected an error when no config has a good signature")
+	}
+}
@@ -23,6 +23,9 @@ import (
 
 func ParseConfig(logger *log.Logger, rawConfig []byte) (types.Config, report.Report, error) {
 	logger.Debug("parsing config: %s", string(rawConfig))
+	if err := logger.Journal().RecordConfig(rawConfig); err != nil {
+		logger.Err("failed to write journal: %v", err)
+	}
 
 	return config.Parse(rawConfig)
 }
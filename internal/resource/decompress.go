@@ -0,0 +1,58 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// cmdReader is the output of a decompressor run as a separate process.
+type cmdReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+// newCmdReader starts the named decompressor with r as its input, and returns
+// its output. The output must be read to the end before it's closed, unless
+// the decompression is being abandoned.
+func newCmdReader(r io.Reader, name string, args ...string) (io.ReadCloser, error) {
+	c := &cmdReader{cmd: exec.Command(name, args...)}
+	c.cmd.Stdin = r
+	c.cmd.Stderr = &c.stderr
+	stdout, err := c.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	c.ReadCloser = stdout
+	if err := c.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close waits for the decompressor to exit, and returns an error if it
+// failed, e.g. because its input was corrupt.
+func (c *cmdReader) Close() error {
+	c.ReadCloser.Close()
+	if err := c.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", c.cmd.Path, err, strings.TrimSpace(c.stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/url"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/internal/log"
+)
+
+// "hello\n", compressed
+const (
+	helloGzip  = "H4sIAAAAAAAAA8tIzcnJ5wIAIDA6NgYAAAA="
+	helloBzip2 = "QlpoOTFBWSZTWcHAgOIAAAFBAAAQAkSgADDNAMNGKZcXckU4UJDBwIDi"
+	helloXz    = "/Td6WFoAAATm1rRGBMAKBiEBFgAAAAAAAAAAAKowjqYBAAVoZWxsbwoAAAClYJfxlPb94AABJgY6kzsKH7bzfQEAAAAABFla"
+	helloZstd  = "KLUv/QRYMQAAaGVsbG8KU4i9kQ=="
+)
+
+func TestUncompress(t *testing.T) {
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	sum := sha256.Sum256([]byte("hello\n"))
+
+	tests := []struct {
+		compression string
+		data        string
+		ok          bool
+	}{
+		{"gzip", helloGzip, true},
+		{"bzip2", helloBzip2, true},
+		{"bzip2", helloGzip, false},
+		// the hash is of the decompressed contents, not of the stream
+		{"", helloGzip, false},
+	}
+
+	for i, test := range tests {
+		u, err := url.Parse("data:;base64," + test.data)
+		if err != nil {
+			t.Fatalf("#%d: bad url: %v", i, err)
+		}
+		data, err := f.FetchToBuffer(*u, FetchOptions{
+			Compression: test.compression,
+			Hash:        sha256.New(),
+			ExpectedSum: sum[:],
+		})
+		if !test.ok {
+			if err == nil {
+				t.Errorf("#%d: expected an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		} else if string(data) != "hello\n" {
+			t.Errorf("#%d: bad data: %q", i, data)
+		}
+	}
+}
+
+func TestCmdReader(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		args []string
+		data string
+	}{
+		{"xz", []string{"--decompress", "--stdout"}, helloXz},
+		{"zstd", []string{"--decompress", "--stdout", "--quiet"}, helloZstd},
+	}
+
+	for _, test := range tests {
+		path, err := exec.LookPath(test.cmd)
+		if err != nil {
+			t.Logf("skipping %s: %v", test.cmd, err)
+			continue
+		}
+		compressed, err := base64.StdEncoding.DecodeString(test.data)
+		if err != nil {
+			t.Fatalf("%s: bad test data: %v", test.cmd, err)
+		}
+
+		r, err := newCmdReader(bytes.NewReader(compressed), path, test.args...)
+		if err != nil {
+			t.Fatalf("%s: couldn't start: %v", test.cmd, err)
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s: couldn't read: %v", test.cmd, err)
+		}
+		if err := r.Close(); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.cmd, err)
+		}
+		if string(data) != "hello\n" {
+			t.Errorf("%s: bad data: %q", test.cmd, data)
+		}
+
+		r, err = newCmdReader(strings.NewReader("garbage"), path, test.args...)
+		if err != nil {
+			t.Fatalf("%s: couldn't start: %v", test.cmd, err)
+		}
+		ioutil.ReadAll(r)
+		if err := r.Close(); err == nil {
+			t.Errorf("%s: expected an error for corrupt input", test.cmd)
+		}
+	}
+}
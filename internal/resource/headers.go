@@ -0,0 +1,43 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"net/http"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+// AddHeaders returns a copy of base with the headers of a remote resource
+// added, replacing any of base's headers with the same name. A header without
+// a value removes that header (including the default User-Agent) instead.
+func AddHeaders(base http.Header, headers []types.HTTPHeader) http.Header {
+	if len(headers) == 0 {
+		return base
+	}
+	h := http.Header{}
+	for key, values := range base {
+		h[key] = append([]string(nil), values...)
+	}
+	for _, header := range headers {
+		key := http.CanonicalHeaderKey(header.Name)
+		if header.Value == nil {
+			h[key] = []string{}
+		} else {
+			h[key] = []string{*header.Value}
+		}
+	}
+	return h
+}
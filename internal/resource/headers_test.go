@@ -0,0 +1,63 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+)
+
+func TestAddHeaders(t *testing.T) {
+	strToPtr := func(p string) *string { return &p }
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	headers := AddHeaders(ConfigHeaders, []types.HTTPHeader{
+		{Name: "authorization", Value: strToPtr("Bearer abc")},
+		{Name: "Accept", Value: strToPtr("application/json")},
+		{Name: "User-Agent"},
+	})
+	if _, err := f.FetchToBuffer(*u, FetchOptions{Headers: headers}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v := got.Get("Authorization"); v != "Bearer abc" {
+		t.Errorf("bad Authorization header: %q", v)
+	}
+	if v := got.Get("Accept"); v != "application/json" {
+		t.Errorf("bad Accept header: %q", v)
+	}
+	if _, ok := got["User-Agent"]; ok {
+		t.Errorf("User-Agent header wasn't removed: %q", got.Get("User-Agent"))
+	}
+	if v := ConfigHeaders.Get("Accept"); v == "application/json" {
+		t.Errorf("base headers were modified")
+	}
+}
@@ -42,6 +42,10 @@ const (
 
 	defaultHttpResponseHeaderTimeout = 10
 	defaultHttpTotalTimeout          = 0
+
+	// alternateSourceTimeout bounds how long a source with alternates is
+	// retried before moving on when no total timeout has been configured.
+	alternateSourceTimeout = time.Minute
 )
 
 var (
@@ -57,14 +61,27 @@ type HttpClient struct {
 	timeout time.Duration
 
 	transport *http.Transport
-	cas       map[types.CaReference][]byte
+	cas       map[caKey][]byte
+}
+
+// caKey identifies a fetched CA. CaReference itself can't be a map key, since
+// it has a list of headers.
+type caKey struct {
+	source       string
+	verification types.Verification
 }
 
-func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, cas []types.CaReference) error {
+// UpdateHttpTimeoutsAndCAs configures the http client with the timeouts,
+// additional certificate authorities and proxy settings of a config. Proxy
+// settings which are unset fall back to f.DefaultProxy.
+func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, cas []types.CaReference, proxy types.Proxy) error {
 	if f.client == nil {
 		f.newHttpClient()
 	}
 
+	// Update the proxy
+	f.client.transport.Proxy = proxyFunc(OverrideProxy(f.DefaultProxy, proxy))
+
 	// Update timeouts
 	responseHeader := defaultHttpResponseHeaderTimeout
 	total := defaultHttpTotalTimeout
@@ -119,7 +136,8 @@ func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, cas []types.
 }
 
 func (f *Fetcher) getCABlob(ca types.CaReference) ([]byte, error) {
-	if blob, ok := f.client.cas[ca]; ok {
+	key := caKey{ca.Source, ca.Verification}
+	if blob, ok := f.client.cas[key]; ok {
 		return blob, nil
 	}
 	u, err := url.Parse(ca.Source)
@@ -146,6 +164,7 @@ func (f *Fetcher) getCABlob(ca types.CaReference) ([]byte, error) {
 	}
 
 	cablob, err := f.FetchToBuffer(*u, FetchOptions{
+		Headers:     AddHeaders(nil, ca.HTTPHeaders),
 		Hash:        hasher,
 		ExpectedSum: expectedSum,
 	})
@@ -153,13 +172,18 @@ func (f *Fetcher) getCABlob(ca types.CaReference) ([]byte, error) {
 		f.Logger.Err("Unable to fetch CA (%s): %s", u, err)
 		return nil, err
 	}
-	f.client.cas[ca] = cablob
+	if err := f.AssertSigned(ca.Verification, ca.HTTPHeaders, cablob); err != nil {
+		f.Logger.Err("Unable to verify CA (%s): %s", u, err)
+		return nil, err
+	}
+	f.client.cas[key] = cablob
 	return cablob, nil
 
 }
 
 // RewriteCAsWithDataUrls will modify the passed in slice of CA references to
-// contain the actual CA file via a dataurl in their source field.
+// contain the actual CA file via a dataurl in their source field. Their
+// signatures, if any, are inlined the same way.
 func (f *Fetcher) RewriteCAsWithDataUrls(cas []types.CaReference) error {
 	for i, ca := range cas {
 		blob, err := f.getCABlob(ca)
@@ -167,13 +191,18 @@ func (f *Fetcher) RewriteCAsWithDataUrls(cas []types.CaReference) error {
 			return err
 		}
 
+		if err := f.InlineSignature(&cas[i].Verification, ca.HTTPHeaders); err != nil {
+			return err
+		}
 		cas[i].Source = dataurl.EncodeBytes(blob)
+		cas[i].HTTPHeaders = nil
 	}
 	return nil
 }
 
 func (f *Fetcher) newHttpClient() {
 	transport := &http.Transport{
+		Proxy:                 proxyFunc(f.DefaultProxy),
 		ResponseHeaderTimeout: time.Duration(defaultHttpResponseHeaderTimeout) * time.Second,
 		Dial: (&net.Dialer{
 			Timeout:   30 * time.Second,
@@ -191,13 +220,14 @@ func (f *Fetcher) newHttpClient() {
 		logger:    f.Logger,
 		timeout:   time.Duration(defaultHttpTotalTimeout) * time.Second,
 		transport: transport,
-		cas:       make(map[types.CaReference][]byte),
+		cas:       make(map[caKey][]byte),
 	}
 }
 
 // getReaderWithHeader performs an HTTP GET on the provided URL with the provided request header
 // and returns the response body Reader, HTTP status code, and error (if any). By
-// default, User-Agent is added to the header but this can be overridden.
+// default, User-Agent is added to the header but this can be overridden, or
+// removed by giving it no values.
 func (c HttpClient) getReaderWithHeader(url string, header http.Header) (io.ReadCloser, int, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -208,6 +238,11 @@ func (c HttpClient) getReaderWithHeader(url string, header http.Header) (io.Read
 
 	for key, values := range header {
 		req.Header.Del(key)
+		if len(values) == 0 {
+			// a present but empty header isn't sent, and stops net/http
+			// from adding its own User-Agent
+			req.Header[key] = nil
+		}
 		for _, value := range values {
 			req.Header.Add(key, value)
 		}
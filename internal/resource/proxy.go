@@ -0,0 +1,153 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+// ProxyFromEnvironment returns the proxy settings given by the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables, or their lowercase forms.
+func ProxyFromEnvironment() types.Proxy {
+	getenv := func(name string) *string {
+		for _, n := range []string{name, strings.ToLower(name)} {
+			if v := os.Getenv(n); v != "" {
+				return &v
+			}
+		}
+		return nil
+	}
+
+	p := types.Proxy{
+		HTTPProxy:  getenv("HTTP_PROXY"),
+		HTTPSProxy: getenv("HTTPS_PROXY"),
+	}
+	if noProxy := getenv("NO_PROXY"); noProxy != nil {
+		p.NoProxy = SplitNoProxy(*noProxy)
+	}
+	return p
+}
+
+// SplitNoProxy splits a comma-separated list of hosts which shouldn't be
+// reached through the proxy, as in the NO_PROXY environment variable.
+func SplitNoProxy(s string) []types.NoProxyItem {
+	items := []types.NoProxyItem{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, types.NoProxyItem(item))
+		}
+	}
+	return items
+}
+
+// OverrideProxy returns base with each of the settings which are set in
+// override replaced.
+func OverrideProxy(base, override types.Proxy) types.Proxy {
+	if override.HTTPProxy != nil {
+		base.HTTPProxy = override.HTTPProxy
+	}
+	if override.HTTPSProxy != nil {
+		base.HTTPSProxy = override.HTTPSProxy
+	}
+	if len(override.NoProxy) > 0 {
+		base.NoProxy = override.NoProxy
+	}
+	return base
+}
+
+// proxyFunc returns a function for http.Transport.Proxy which sends requests
+// through the proxy p has for their scheme, unless their host is one of p's
+// exceptions or is the local host.
+func proxyFunc(p types.Proxy) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		var proxy *string
+		switch req.URL.Scheme {
+		case "http":
+			proxy = p.HTTPProxy
+		case "https":
+			proxy = p.HTTPSProxy
+		}
+		if proxy == nil || *proxy == "" || !useProxy(p.NoProxy, req.URL) {
+			return nil, nil
+		}
+		return parseProxy(*proxy)
+	}
+}
+
+// parseProxy parses a proxy url, which is assumed to be an http url if it has
+// no scheme (e.g. "proxy.example.com:3128"), as is common in the environment.
+func parseProxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if u, err := url.Parse("http://" + s); err == nil {
+			return u, nil
+		}
+	}
+	return u, err
+}
+
+// useProxy returns false if u's host matches one of the noProxy entries, which
+// are hosts (matching their subdomains too), IP addresses, CIDR ranges, any of
+// those with a port, or "*" for every host.
+func useProxy(noProxy []types.NoProxyItem, u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	for _, item := range noProxy {
+		entry := strings.ToLower(string(item))
+		if entry == "*" {
+			return false
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return false
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return false
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestProxyFunc(t *testing.T) {
+	strToPtr := func(p string) *string { return &p }
+	proxy := types.Proxy{
+		HTTPProxy:  strToPtr("http://proxy:3128"),
+		HTTPSProxy: strToPtr("secure-proxy:3129"),
+		NoProxy:    []types.NoProxyItem{"internal.example.com", ".corp", "10.0.0.0/8", "192.168.1.1", "other.example.com:8080"},
+	}
+
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"http://example.com/config.ign", "http://proxy:3128"},
+		{"https://example.com/config.ign", "http://secure-proxy:3129"},
+		{"http://internal.example.com/config.ign", ""},
+		{"http://a.internal.example.com/config.ign", ""},
+		{"http://notinternal.example.com/config.ign", "http://proxy:3128"},
+		{"http://host.corp/config.ign", ""},
+		{"http://10.1.2.3/config.ign", ""},
+		{"http://11.1.2.3/config.ign", "http://proxy:3128"},
+		{"http://192.168.1.1:8080/config.ign", ""},
+		{"http://other.example.com:8080/config.ign", ""},
+		{"http://other.example.com/config.ign", "http://proxy:3128"},
+		{"http://localhost/config.ign", ""},
+		{"http://127.0.0.1/config.ign", ""},
+	}
+
+	f := proxyFunc(proxy)
+	for i, test := range tests {
+		req, err := http.NewRequest("GET", test.in, nil)
+		if err != nil {
+			t.Fatalf("#%d: bad request: %v", i, err)
+		}
+		u, err := f(req)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		out := ""
+		if u != nil {
+			out = u.String()
+		}
+		if out != test.out {
+			t.Errorf("#%d: bad proxy: want %q, got %q", i, test.out, out)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	if u, _ := proxyFunc(types.Proxy{NoProxy: []types.NoProxyItem{"*"}, HTTPProxy: strToPtr("proxy")})(req); u != nil {
+		t.Errorf("expected * to match every host, got %v", u)
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Unsetenv(name)
+	}
+	os.Setenv("http_proxy", "http://proxy:3128")
+	os.Setenv("NO_PROXY", "example.com, .corp,")
+
+	httpProxy := "http://proxy:3128"
+	expected := types.Proxy{
+		HTTPProxy: &httpProxy,
+		NoProxy:   []types.NoProxyItem{"example.com", ".corp"},
+	}
+	if p := ProxyFromEnvironment(); !reflect.DeepEqual(expected, p) {
+		t.Errorf("bad proxy: want %+v, got %+v", expected, p)
+	}
+
+	override := "http://other:3128"
+	expected.HTTPProxy = &override
+	if p := OverrideProxy(ProxyFromEnvironment(), types.Proxy{HTTPProxy: &override}); !reflect.DeepEqual(expected, p) {
+		t.Errorf("bad overridden proxy: want %+v, got %+v", expected, p)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/util"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+// AssertSigned checks data against the detached signature referenced by
+// verify, if there is one, using the keys in f.Keyring. The signature is
+// fetched with the headers of the resource it signs. Without trusted keys the
+// signature can't be checked, so it is only logged.
+func (f *Fetcher) AssertSigned(verify types.Verification, headers []types.HTTPHeader, data []byte) error {
+	return f.AssertSignedDigest(verify, headers, signatureDigest(data))
+}
+
+// AssertSignedDigest is AssertSigned for data whose digest, as computed by
+// util.NewSignatureHash (e.g. via FetchOptions.Digest), is sum.
+func (f *Fetcher) AssertSignedDigest(verify types.Verification, headers []types.HTTPHeader, sum []byte) error {
+	if verify.Signature == nil {
+		return nil
+	}
+	if len(f.Keyring) == 0 {
+		f.Logger.Warning("not checking signature: no keys are trusted")
+		return nil
+	}
+	u, err := url.Parse(*verify.Signature)
+	if err != nil {
+		return err
+	}
+	return f.assertSignedBy(*u, AddHeaders(nil, headers), sum)
+}
+
+// AssertSignedBy checks data against the detached signature at u, fetched with
+// the given headers, using the keys in f.Keyring.
+func (f *Fetcher) AssertSignedBy(u url.URL, headers http.Header, data []byte) error {
+	return f.assertSignedBy(u, headers, signatureDigest(data))
+}
+
+func signatureDigest(data []byte) []byte {
+	h := util.NewSignatureHash()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func (f *Fetcher) assertSignedBy(u url.URL, headers http.Header, sum []byte) error {
+	signature, err := f.FetchToBuffer(u, FetchOptions{Headers: headers})
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature: %v", err)
+	}
+	return f.Keyring.VerifyDigest(sum, signature)
+}
+
+// InlineSignature replaces the url of the signature referenced by verify, if
+// there is one, with a data url holding the signature, so that it can still
+// be checked once the resource it signs has been inlined. The signature is
+// fetched with the headers of that resource.
+func (f *Fetcher) InlineSignature(verify *types.Verification, headers []types.HTTPHeader) error {
+	if verify.Signature == nil {
+		return nil
+	}
+	u, err := url.Parse(*verify.Signature)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "data" {
+		return nil
+	}
+	signature, err := f.FetchToBuffer(*u, FetchOptions{
+		Headers: AddHeaders(nil, headers),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature: %v", err)
+	}
+	s := dataurl.EncodeBytes(signature)
+	verify.Signature = &s
+	return nil
+}
@@ -16,6 +16,7 @@ package resource
 
 import (
 	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"context"
 	"encoding/hex"
@@ -48,11 +49,10 @@ import (
 )
 
 var (
-	ErrSchemeUnsupported      = errors.New("unsupported source scheme")
-	ErrPathNotAbsolute        = errors.New("path is not absolute")
-	ErrNotFound               = errors.New("resource not found")
-	ErrFailed                 = errors.New("failed to fetch resource")
-	ErrCompressionUnsupported = errors.New("compression is not supported with that scheme")
+	ErrSchemeUnsupported = errors.New("unsupported source scheme")
+	ErrPathNotAbsolute   = errors.New("path is not absolute")
+	ErrNotFound          = errors.New("resource not found")
+	ErrFailed            = errors.New("failed to fetch resource")
 
 	// ConfigHeaders are the HTTP headers that should be used when the Ignition
 	// config is being fetched
@@ -84,6 +84,15 @@ type Fetcher struct {
 	// The region where the EC2 machine trying to fetch is.
 	// This is used as a hint to fetch the S3 bucket from the right partition and region.
 	S3RegionHint string
+
+	// Keyring holds the public keys trusted to sign configs and files. If
+	// it's empty, signatures can't be checked and aren't required.
+	Keyring util.Keyring
+
+	// DefaultProxy holds the proxy settings used for fetches over HTTP(S)
+	// when a config's proxy settings leave them unset. It's typically read
+	// from the environment and the kernel cmdline.
+	DefaultProxy types.Proxy
 }
 
 type FetchOptions struct {
@@ -102,6 +111,11 @@ type FetchOptions struct {
 	// Compression specifies the type of compression to use when decompressing
 	// the fetched object. If left empty, no decompression will be used.
 	Compression string
+
+	// Digest, if non-nil, is also written the fetched (and decompressed)
+	// object, e.g. so that a detached signature can be checked without
+	// reading the object again. It is reset before each fetch.
+	Digest hash.Hash
 }
 
 // FetchToBuffer will fetch the given url into a temporrary file, and then read
@@ -129,6 +143,43 @@ func (f *Fetcher) FetchToBuffer(u url.URL, opts FetchOptions) ([]byte, error) {
 	return res, nil
 }
 
+// FetchToBufferFromAny tries each of the given urls in order, returning the
+// contents of the first one that can be fetched and that check, given the url
+// and its contents, accepts, along with the url it came from. check may be nil. Every url but the last is given
+// up on after a minute if no total http timeout has been configured, so that
+// an unreachable server doesn't prevent the rest from being tried. The error
+// from the last url is returned if none succeed.
+func (f *Fetcher) FetchToBufferFromAny(us []url.URL, opts FetchOptions, check func(url.URL, []byte) error) ([]byte, url.URL, error) {
+	if f.client == nil {
+		f.newHttpClient()
+	}
+
+	err := ErrNotFound
+	for i, u := range us {
+		fetcher := *f
+		if i < len(us)-1 && f.client.timeout == 0 {
+			client := *f.client
+			client.timeout = alternateSourceTimeout
+			fetcher.client = &client
+		}
+
+		var data []byte
+		data, err = fetcher.FetchToBuffer(u, opts)
+		f.AWSSession = fetcher.AWSSession
+		if err == nil && check != nil {
+			err = check(u, data)
+		}
+		if err == nil {
+			return data, u, nil
+		}
+
+		if i < len(us)-1 {
+			f.Logger.Warning("failed to fetch source %d of %d, trying the next one: %v", i+1, len(us), err)
+		}
+	}
+	return nil, url.URL{}, err
+}
+
 // Fetch calls the appropriate FetchFrom* function based on the scheme of the
 // given URL. The results will be decompressed if compression is set in opts,
 // and written into dest. If opts.Hash is set the data stream will also be
@@ -153,6 +204,21 @@ func (f *Fetcher) Fetch(u url.URL, dest *os.File, opts FetchOptions) error {
 	}
 }
 
+// Prepare creates any state that fetching u would otherwise create lazily
+// (e.g. the http client or the AWS session), so that the fetcher can then be
+// used to fetch u from multiple goroutines at once.
+func (f *Fetcher) Prepare(u url.URL) error {
+	switch u.Scheme {
+	case "http", "https":
+		if f.client == nil {
+			f.newHttpClient()
+		}
+	case "s3":
+		return f.newAWSSession()
+	}
+	return nil
+}
+
 // FetchFromTFTP fetches a resource from u via TFTP into dest, returning an
 // error if one is encountered.
 func (f *Fetcher) FetchFromTFTP(u url.URL, dest *os.File, opts FetchOptions) error {
@@ -242,9 +308,6 @@ func (f *Fetcher) FetchFromHTTP(u url.URL, dest *os.File, opts FetchOptions) err
 // FetchFromDataURL writes the data stored in the dataurl u into dest, returning
 // an error if one is encountered.
 func (f *Fetcher) FetchFromDataURL(u url.URL, dest *os.File, opts FetchOptions) error {
-	if opts.Compression != "" {
-		return ErrCompressionUnsupported
-	}
 	url, err := dataurl.DecodeString(u.String())
 	if err != nil {
 		return err
@@ -299,9 +362,6 @@ func (f *Fetcher) FetchFromOEM(u url.URL, dest *os.File, opts FetchOptions) erro
 // IAM credentials from the EC2 metadata service, and if this fails will attempt
 // to fetch the object with anonymous credentials.
 func (f *Fetcher) FetchFromS3(u url.URL, dest *os.File, opts FetchOptions) error {
-	if opts.Compression != "" {
-		return ErrCompressionUnsupported
-	}
 	ctx := context.Background()
 	if f.client != nil && f.client.timeout != 0 {
 		var cancelFn context.CancelFunc
@@ -309,14 +369,8 @@ func (f *Fetcher) FetchFromS3(u url.URL, dest *os.File, opts FetchOptions) error
 		defer cancelFn()
 	}
 
-	if f.AWSSession == nil {
-		var err error
-		f.AWSSession, err = session.NewSession(&aws.Config{
-			Credentials: credentials.AnonymousCredentials,
-		})
-		if err != nil {
-			return err
-		}
+	if err := f.newAWSSession(); err != nil {
+		return err
 	}
 	sess := f.AWSSession.Copy()
 
@@ -339,20 +393,38 @@ func (f *Fetcher) FetchFromS3(u url.URL, dest *os.File, opts FetchOptions) error
 		Bucket: &u.Host,
 		Key:    &u.Path,
 	}
+	if opts.Compression != "" {
+		// The downloader writes chunks of the object out of order, so the
+		// object has to be downloaded in full before it's decompressed.
+		tmp, err := ioutil.TempFile(filepath.Dir(dest.Name()), "s3")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if err := f.fetchFromS3WithCreds(ctx, tmp, input, sess); err != nil {
+			return err
+		}
+		if _, err := tmp.Seek(0, os.SEEK_SET); err != nil {
+			return err
+		}
+		return f.decompressCopyHashAndVerify(dest, tmp, opts)
+	}
 	err = f.fetchFromS3WithCreds(ctx, dest, input, sess)
 	if err != nil {
 		return err
 	}
-	if opts.Hash != nil {
-		opts.Hash.Reset()
+	if hashes := opts.hashes(); len(hashes) > 0 {
 		_, err = dest.Seek(0, os.SEEK_SET)
 		if err != nil {
 			return err
 		}
-		_, err = io.Copy(opts.Hash, dest)
+		_, err = io.Copy(io.MultiWriter(hashes...), dest)
 		if err != nil {
 			return err
 		}
+	}
+	if opts.Hash != nil {
 		calculatedSum := opts.Hash.Sum(nil)
 		if !bytes.Equal(calculatedSum, opts.ExpectedSum) {
 			return util.ErrHashMismatch{
@@ -365,6 +437,19 @@ func (f *Fetcher) FetchFromS3(u url.URL, dest *os.File, opts FetchOptions) error
 	return nil
 }
 
+// newAWSSession initializes f.AWSSession with anonymous credentials if it is
+// not already set.
+func (f *Fetcher) newAWSSession() error {
+	if f.AWSSession != nil {
+		return nil
+	}
+	var err error
+	f.AWSSession, err = session.NewSession(&aws.Config{
+		Credentials: credentials.AnonymousCredentials,
+	})
+	return err
+}
+
 func (f *Fetcher) fetchFromS3WithCreds(ctx context.Context, dest *os.File, input *s3.GetObjectInput, sess *session.Session) error {
 	downloader := s3manager.NewDownloader(sess)
 	_, err := downloader.DownloadWithContext(ctx, dest, input)
@@ -382,17 +467,36 @@ func (f *Fetcher) fetchFromS3WithCreds(ctx context.Context, dest *os.File, input
 
 // uncompress will wrap the given io.Reader in a decompresser specified in the
 // FetchOptions, and return an io.ReadCloser with the decompressed data stream.
+// xz and zstd streams are decompressed by the distro's xz and zstd tools.
 func (f *Fetcher) uncompress(r io.Reader, opts FetchOptions) (io.ReadCloser, error) {
 	switch opts.Compression {
 	case "":
 		return ioutil.NopCloser(r), nil
 	case "gzip":
 		return gzip.NewReader(r)
+	case "bzip2":
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	case "xz":
+		return newCmdReader(r, distro.XzCmd(), "--decompress", "--stdout")
+	case "zstd":
+		return newCmdReader(r, distro.ZstdCmd(), "--decompress", "--stdout", "--quiet")
 	default:
 		return nil, types.ErrCompressionInvalid
 	}
 }
 
+// hashes resets and returns the hashes the fetched object is to be written to.
+func (opts FetchOptions) hashes() []io.Writer {
+	hashes := []io.Writer{}
+	for _, h := range []hash.Hash{opts.Hash, opts.Digest} {
+		if h != nil {
+			h.Reset()
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
+
 // decompressCopyHashAndVerify will decompress src if necessary, copy src into
 // dest until src returns an io.EOF while also calculating a hash if one is set,
 // and will return an error if there's any problems with any of this or if the
@@ -402,12 +506,13 @@ func (f *Fetcher) decompressCopyHashAndVerify(dest io.Writer, src io.Reader, opt
 	if err != nil {
 		return err
 	}
-	defer decompressor.Close()
-	if opts.Hash != nil {
-		opts.Hash.Reset()
-		dest = io.MultiWriter(dest, opts.Hash)
-	}
+	dest = io.MultiWriter(append([]io.Writer{dest}, opts.hashes()...)...)
 	_, err = io.Copy(dest, decompressor)
+	// a decompressor running as a separate process only reports corrupt
+	// input when it's closed
+	if closeErr := decompressor.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return err
 	}
@@ -17,13 +17,21 @@ package sgdisk
 import (
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 
 	"github.com/coreos/ignition/internal/distro"
-	"github.com/coreos/ignition/internal/log"
 )
 
+// Runner runs the commands that make up an Operation, e.g. the stage's
+// util.Util, which either runs them via LogCmd or records them in a plan.
+type Runner interface {
+	Info(format string, a ...interface{}) error
+	RunCmd(target string, cmd *exec.Cmd, format string, a ...interface{}) (int, error)
+}
+
 type Operation struct {
-	logger *log.Logger
+	runner Runner
 	dev    string
 	wipe   bool
 	parts  []Partition
@@ -39,8 +47,8 @@ type Partition struct {
 }
 
 // Begin begins an sgdisk operation
-func Begin(logger *log.Logger, dev string) *Operation {
-	return &Operation{logger: logger, dev: dev}
+func Begin(runner Runner, dev string) *Operation {
+	return &Operation{runner: runner, dev: dev}
 }
 
 // CreatePartition adds the supplied partition to the list of partitions to be created as part of an operation.
@@ -58,10 +66,10 @@ func (op *Operation) WipeTable(wipe bool) {
 func (op *Operation) Commit() error {
 	if op.wipe {
 		cmd := exec.Command(distro.SgdiskCmd(), "--zap-all", op.dev)
-		if _, err := op.logger.LogCmd(cmd, "wiping table on %q", op.dev); err != nil {
-			op.logger.Info("potential error encountered while wiping table... retrying")
+		if _, err := op.runner.RunCmd(op.dev, cmd, "wiping table on %q", op.dev); err != nil {
+			op.runner.Info("potential error encountered while wiping table... retrying")
 			cmd = exec.Command(distro.SgdiskCmd(), "--zap-all", op.dev)
-			if _, err := op.logger.LogCmd(cmd, "wiping table on %q", op.dev); err != nil {
+			if _, err := op.runner.RunCmd(op.dev, cmd, "wiping table on %q", op.dev); err != nil {
 				return fmt.Errorf("wipe failed: %v", err)
 			}
 		}
@@ -81,10 +89,94 @@ func (op *Operation) Commit() error {
 		}
 		opts = append(opts, op.dev)
 		cmd := exec.Command(distro.SgdiskCmd(), opts...)
-		if _, err := op.logger.LogCmd(cmd, "creating %d partitions on %q", len(op.parts), op.dev); err != nil {
+		if _, err := op.runner.RunCmd(op.dev, cmd, "creating %d partitions on %q", len(op.parts), op.dev); err != nil {
 			return fmt.Errorf("create partitions failed: %v", err)
 		}
 	}
 
 	return nil
 }
+
+// ReadTable returns the partitions in the partition table of dev, with their
+// offsets and lengths in device logical sectors. A device without a partition
+// table has no partitions.
+func ReadTable(dev string) ([]Partition, error) {
+	out, err := exec.Command(distro.SgdiskCmd(), "--print", dev).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partition table of %q: %v", dev, err)
+	}
+	parts := []Partition{}
+	for _, n := range parseNumbers(string(out)) {
+		out, err := exec.Command(distro.SgdiskCmd(), fmt.Sprintf("--info=%d", n), dev).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partition %d of %q: %v", n, dev, err)
+		}
+		p := parseInfo(string(out))
+		p.Number = n
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
+
+// parseNumbers returns the partition numbers listed by "sgdisk --print", which
+// are the first column of the rows following the "Number" header.
+func parseNumbers(out string) []int {
+	numbers := []int{}
+	table := false
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "Number" {
+			table = true
+			continue
+		}
+		if !table {
+			continue
+		}
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// parseInfo parses the output of "sgdisk --info", e.g.
+//
+//	Partition GUID code: 0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)
+//	Partition unique GUID: 1D4F0C36-9E2F-4B9A-8E3B-8F0B2B4C6B1E
+//	First sector: 2048 (at 1024.0 KiB)
+//	Last sector: 4196351 (at 2.0 GiB)
+//	Partition size: 4194304 sectors (2.0 GiB)
+//	Attribute flags: 0000000000000000
+//	Partition name: 'ROOT'
+func parseInfo(out string) Partition {
+	p := Partition{}
+	firstField := func(s string) string {
+		if fields := strings.Fields(s); len(fields) > 0 {
+			return fields[0]
+		}
+		return ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		switch parts[0] {
+		case "Partition GUID code":
+			p.TypeGUID = firstField(value)
+		case "Partition unique GUID":
+			p.GUID = firstField(value)
+		case "First sector":
+			p.Offset, _ = strconv.ParseUint(firstField(value), 10, 64)
+		case "Partition size":
+			p.Length, _ = strconv.ParseUint(firstField(value), 10, 64)
+		case "Partition name":
+			p.Label = strings.TrimSuffix(strings.TrimPrefix(value, "'"), "'")
+		}
+	}
+	return p
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sgdisk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNumbers(t *testing.T) {
+	tests := []struct {
+		in  string
+		out []int
+	}{
+		{
+			in: `Creating new GPT entries in memory.
+Disk /dev/vdb: 20971520 sectors, 10.0 GiB
+Logical sector size: 512 bytes
+Total free space is 20971453 sectors (10.0 GiB)
+
+Number  Start (sector)    End (sector)  Size       Code  Name
+`,
+			out: []int{},
+		},
+		{
+			in: `Disk /dev/vda: 20971520 sectors, 10.0 GiB
+Partition table holds up to 128 entries
+First usable sector is 34, last usable sector is 20971486
+Total free space is 2014 sectors (1007.0 KiB)
+
+Number  Start (sector)    End (sector)  Size       Code  Name
+   1            4096          266239   128.0 MiB   EF00  EFI-SYSTEM
+   9         4458496        20971486   7.9 GiB     8300  ROOT
+`,
+			out: []int{1, 9},
+		},
+	}
+
+	for i, test := range tests {
+		if out := parseNumbers(test.in); !reflect.DeepEqual(test.out, out) {
+			t.Errorf("#%d: bad numbers: want %v, got %v", i, test.out, out)
+		}
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	in := `Partition GUID code: 0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem)
+Partition unique GUID: 1D4F0C36-9E2F-4B9A-8E3B-8F0B2B4C6B1E
+First sector: 2048 (at 1024.0 KiB)
+Last sector: 4196351 (at 2.0 GiB)
+Partition size: 4194304 sectors (2.0 GiB)
+Attribute flags: 0000000000000000
+Partition name: 'ROOT: main'
+`
+	expected := Partition{
+		Offset:   2048,
+		Length:   4194304,
+		Label:    "ROOT: main",
+		TypeGUID: "0FC63DAF-8483-4772-8E79-3D69D8477DE4",
+		GUID:     "1D4F0C36-9E2F-4B9A-8E3B-8F0B2B4C6B1E",
+	}
+	if out := parseInfo(in); !reflect.DeepEqual(expected, out) {
+		t.Errorf("bad partition: want %+v, got %+v", expected, out)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+var (
+	ErrSignatureInvalid  = errors.New("signature verification failed")
+	ErrSignatureRequired = errors.New("signatures are required since trusted keys are installed")
+	ErrNoPublicKeys      = errors.New("no PEM-encoded public keys found")
+)
+
+// Keyring is the set of public keys trusted to sign configs and files.
+// Signatures are detached SHA-256 signatures made with an RSA (PKCS #1 v1.5)
+// or ECDSA key, as produced by `openssl dgst -sha256 -sign`.
+type Keyring []crypto.PublicKey
+
+// ParsePublicKeys parses the PEM-encoded "PUBLIC KEY" blocks in data, as
+// written by `openssl pkey -pubout`. Other blocks are skipped.
+func ParsePublicKeys(data []byte) (Keyring, error) {
+	keys := Keyring{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoPublicKeys
+	}
+	return keys, nil
+}
+
+// NewSignatureHash returns a hash computing the digest which signatures are
+// made over, so that large contents can be verified as they are streamed.
+func NewSignatureHash() hash.Hash {
+	return sha256.New()
+}
+
+// Verify returns nil if signature is a valid signature of data by any of the
+// keys, and ErrSignatureInvalid otherwise.
+func (k Keyring) Verify(data, signature []byte) error {
+	sum := sha256.Sum256(data)
+	return k.VerifyDigest(sum[:], signature)
+}
+
+// VerifyDigest is Verify for data whose digest, as computed by
+// NewSignatureHash, is sum.
+func (k Keyring) VerifyDigest(sum, signature []byte) error {
+	for _, key := range k {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, sum, signature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			var sig struct {
+				R, S *big.Int
+			}
+			if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) != 0 {
+				continue
+			}
+			if ecdsa.Verify(key, sum, sig.R, sig.S) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureInvalid
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("couldn't marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestKeyringVerify(t *testing.T) {
+	data := []byte("hello")
+	sum := sha256.Sum256(data)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("couldn't generate key: %v", err)
+	}
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("couldn't sign: %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("couldn't generate key: %v", err)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, sum[:])
+	if err != nil {
+		t.Fatalf("couldn't sign: %v", err)
+	}
+	ecSig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatalf("couldn't marshal signature: %v", err)
+	}
+
+	// a certificate block before the keys is skipped
+	keyring, err := ParsePublicKeys(append(append([]byte("-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n"),
+		publicKeyPEM(t, &rsaKey.PublicKey)...), publicKeyPEM(t, &ecKey.PublicKey)...))
+	if err != nil {
+		t.Fatalf("couldn't parse keys: %v", err)
+	}
+	if len(keyring) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keyring))
+	}
+
+	tests := []struct {
+		keyring   Keyring
+		data      []byte
+		signature []byte
+		err       error
+	}{
+		{keyring, data, rsaSig, nil},
+		{keyring, data, ecSig, nil},
+		{keyring, []byte("goodbye"), rsaSig, ErrSignatureInvalid},
+		{keyring, []byte("goodbye"), ecSig, ErrSignatureInvalid},
+		{keyring, data, []byte("garbage"), ErrSignatureInvalid},
+		{keyring[1:], data, rsaSig, ErrSignatureInvalid},
+		{Keyring{}, data, ecSig, ErrSignatureInvalid},
+	}
+
+	for i, test := range tests {
+		if err := test.keyring.Verify(test.data, test.signature); err != test.err {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.err, err)
+		}
+	}
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	if _, err := ParsePublicKeys([]byte("not a key")); err != ErrNoPublicKeys {
+		t.Errorf("bad error: want %v, got %v", ErrNoPublicKeys, err)
+	}
+	if _, err := ParsePublicKeys([]byte("-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n")); err == nil {
+		t.Errorf("expected an error for a malformed key")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Generates the catalogue of validation codes from the errors registered with
+// the report package, or checks that the existing catalogue is up to date.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	// imported for the errors they register
+	_ "github.com/coreos/ignition/config"
+	_ "github.com/coreos/ignition/config/lint"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+const header = `# Validation Codes
+
+<!-- Generated by internal/util/tools/codes. Do not edit. -->
+
+Every finding in a validation report carries a stable code identifying the kind of problem found. Codes are never changed or reused, so unlike the messages of findings, whose wording may change, they are safe for tooling to match on. Findings which aren't errors can be hidden by code with ` + "`ignition-validate --suppress`" + `.
+
+| Code | Description |
+|------|-------------|
+`
+
+func main() {
+	flags := struct {
+		help  bool
+		check bool
+		out   string
+	}{}
+
+	flag.BoolVar(&flags.help, "help", false, "Print help and exit.")
+	flag.BoolVar(&flags.check, "check", false, "Check that the catalogue is up to date instead of writing it.")
+	flag.StringVar(&flags.out, "out", "doc/validation-codes.md", "Path to the catalogue.")
+
+	flag.Parse()
+
+	if flags.help {
+		flag.Usage()
+		return
+	}
+
+	catalogue := generate()
+
+	if flags.check {
+		existing, err := ioutil.ReadFile(flags.out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read catalogue: %v\n", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(existing, catalogue) {
+			fmt.Fprintf(os.Stderr, "%s is out of date, regenerate it with \"go run internal/util/tools/codes/codes.go\"\n", flags.out)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := ioutil.WriteFile(flags.out, catalogue, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write catalogue: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate() []byte {
+	var b bytes.Buffer
+	b.WriteString(header)
+	for _, e := range report.Codes() {
+		fmt.Fprintf(&b, "| %s | %s |\n", e.Code, strings.Replace(e.Message, "|", `\|`, -1))
+	}
+	return b.Bytes()
+}
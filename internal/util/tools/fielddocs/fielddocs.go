@@ -0,0 +1,140 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Generates the field descriptions shown by ignition-lsp from the
+// specification of the latest config version, or checks that the existing
+// descriptions are up to date.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const header = `// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// generated by "go run internal/util/tools/fielddocs/fielddocs.go" -- DO NOT EDIT
+
+package main
+
+// fieldDocs maps the path of each field of the config, with list indices left
+// out, to its type and description in the spec.
+var fieldDocs = map[string]fieldDoc{
+`
+
+var (
+	// matches entries such as `  * **_mode_** (integer): the file's mode.`
+	entryRegex = regexp.MustCompile(`^( *)\* \*\*_?([A-Za-z0-9]+)_?\*\* \(([^)]*)\):? ?(.*)$`)
+	// matches reference-style links such as `[text][ref]`
+	linkRegex = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+)
+
+func main() {
+	flags := struct {
+		help  bool
+		check bool
+		spec  string
+		out   string
+	}{}
+
+	flag.BoolVar(&flags.help, "help", false, "Print help and exit.")
+	flag.BoolVar(&flags.check, "check", false, "Check that the descriptions are up to date instead of writing them.")
+	flag.StringVar(&flags.spec, "spec", "doc/configuration-v2_2-experimental.md", "Path to the specification.")
+	flag.StringVar(&flags.out, "out", "lsp/fielddocs.go", "Path to the generated descriptions.")
+
+	flag.Parse()
+
+	if flags.help {
+		flag.Usage()
+		return
+	}
+
+	spec, err := ioutil.ReadFile(flags.spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read specification: %v\n", err)
+		os.Exit(1)
+	}
+	docs, err := generate(spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate descriptions: %v\n", err)
+		os.Exit(1)
+	}
+
+	if flags.check {
+		existing, err := ioutil.ReadFile(flags.out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read descriptions: %v\n", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(existing, docs) {
+			fmt.Fprintf(os.Stderr, "%s is out of date, regenerate it with \"go run internal/util/tools/fielddocs/fielddocs.go\"\n", flags.out)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := ioutil.WriteFile(flags.out, docs, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write descriptions: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate reads the nested list of fields in the spec, which is indented by
+// two spaces per level, and returns the Go source of the descriptions.
+func generate(spec []byte) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(header)
+
+	path := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(spec))
+	for scanner.Scan() {
+		m := entryRegex.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		depth := len(m[1]) / 2
+		if depth > len(path) {
+			return nil, fmt.Errorf("entry %q is nested more than one level below its parent", m[2])
+		}
+		path = append(path[:depth], m[2])
+		description := linkRegex.ReplaceAllString(m[4], "$1")
+		fmt.Fprintf(&b, "\t%q: {%q, %q},\n", strings.Join(path, "."), m[3], description)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
@@ -15,6 +15,7 @@
 package util
 
 import (
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
@@ -36,34 +37,28 @@ func (e ErrHashMismatch) Error() string {
 }
 
 func AssertValid(verify types.Verification, data []byte) error {
-	if hash := verify.Hash; hash != nil {
-		hashFunc, hashSum, err := verify.HashParts()
-		if err != nil {
-			return err
-		}
-
-		var sum []byte
-		switch hashFunc {
-		case "sha512":
-			rawSum := sha512.Sum512(data)
-			sum = rawSum[:]
-		default:
-			return types.ErrHashUnrecognized
-		}
+	hasher, err := GetHasher(verify)
+	if err != nil || hasher == nil {
+		return err
+	}
+	// explicitly ignoring the error here because GetHasher already parsed
+	// the hash
+	_, hashSum, _ := verify.HashParts()
 
-		encodedSum := make([]byte, hex.EncodedLen(len(sum)))
-		hex.Encode(encodedSum, sum)
-		if string(encodedSum) != hashSum {
-			return ErrHashMismatch{
-				Calculated: string(encodedSum),
-				Expected:   hashSum,
-			}
+	hasher.Write(data)
+	encodedSum := hex.EncodeToString(hasher.Sum(nil))
+	if encodedSum != hashSum {
+		return ErrHashMismatch{
+			Calculated: encodedSum,
+			Expected:   hashSum,
 		}
 	}
 
 	return nil
 }
 
+// GetHasher returns a hasher for the hash function of verify, or nil if verify
+// has no hash. sha256, sha384, and sha512 are supported.
 func GetHasher(verify types.Verification) (hash.Hash, error) {
 	if verify.Hash == nil {
 		return nil, nil
@@ -75,6 +70,10 @@ func GetHasher(verify types.Verification) (hash.Hash, error) {
 	}
 
 	switch function {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha384":
+		return sha512.New384(), nil
 	case "sha512":
 		return sha512.New(), nil
 	default:
@@ -49,6 +49,36 @@ func TestAssertValid(t *testing.T) {
 			},
 			out: out{},
 		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+				},
+				data: []byte("hello"),
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha384-59e1748777448c69de6b800d7a33bbfb9ff1b463e44354c3553bcdb9c666fa90125a3c79f90397bdf5f6a13de828684f"),
+				},
+				data: []byte("hello"),
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+				},
+				data: []byte("hello"),
+			},
+			out: out{err: ErrHashMismatch{
+				Calculated: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				Expected:   "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			}},
+		},
 		{
 			in: in{
 				verification: types.Verification{
@@ -57,6 +87,14 @@ func TestAssertValid(t *testing.T) {
 			},
 			out: out{err: types.ErrHashUnrecognized},
 		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha512"),
+				},
+			},
+			out: out{err: types.ErrHashMalformed},
+		},
 		{
 			in: in{
 				verification: types.Verification{
@@ -78,3 +116,34 @@ func TestAssertValid(t *testing.T) {
 		}
 	}
 }
+
+func TestGetHasher(t *testing.T) {
+	stringDeref := func(s string) *string { return &s }
+
+	tests := []struct {
+		in   types.Verification
+		size int
+		err  error
+	}{
+		{in: types.Verification{}},
+		{in: types.Verification{Hash: stringDeref("sha256-0123")}, size: 32},
+		{in: types.Verification{Hash: stringDeref("sha384-0123")}, size: 48},
+		{in: types.Verification{Hash: stringDeref("sha512-0123")}, size: 64},
+		{in: types.Verification{Hash: stringDeref("md5-0123")}, err: types.ErrHashUnrecognized},
+	}
+
+	for i, test := range tests {
+		hasher, err := GetHasher(test.in)
+		if err != test.err {
+			t.Errorf("#%d: bad err: want %v, got %v", i, test.err, err)
+			continue
+		}
+		size := 0
+		if hasher != nil {
+			size = hasher.Size()
+		}
+		if size != test.size {
+			t.Errorf("#%d: bad hash size: want %d, got %d", i, test.size, size)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+type fieldDoc struct {
+	Type        string
+	Description string
+}
+
+type value struct {
+	Value       string
+	Description string
+}
+
+var (
+	// well-known partition types, see
+	// https://en.wikipedia.org/wiki/GUID_Partition_Table#Partition_type_GUIDs
+	partitionTypes = []value{
+		{"0FC63DAF-8483-4772-8E79-3D69D8477DE4", "Linux filesystem data"},
+		{"C12A7328-F81F-11D2-BA4B-00A0C93EC93B", "EFI System partition"},
+		{"21686148-6449-6E6F-744E-656564454649", "BIOS boot partition"},
+		{"0657FD6D-A4AB-43C4-84E5-0933C84B4F4F", "Linux swap"},
+		{"A19D880F-05FC-4D3B-A006-743F0F84911E", "Linux RAID"},
+		{"E6D6D379-F507-44C2-A23C-238F2A3DF928", "Linux LVM"},
+		{"4F68BCE3-E8CD-4DB1-96E7-FBCAF984B709", "Linux root (x86-64)"},
+		{"933AC7E1-2EB4-4F13-B844-0E14E2AEF915", "Linux /home"},
+		{"5DFBF5F4-2848-4BAC-AA5E-0D9A20B745A6", "CoreOS /usr"},
+		{"3884DD41-8582-4404-B9A8-E9B84F2DF50E", "CoreOS resizable root"},
+		{"C95DC21A-DF0E-4340-8D7B-26CBFA9A03E0", "CoreOS reserved"},
+		{"BE9067B9-EA49-4F15-B4F6-F36F8C9E1818", "CoreOS root on RAID"},
+	}
+
+	// the formats accepted by Mount.Validate
+	filesystemFormats = []value{
+		{"ext4", "ext4 filesystem"},
+		{"btrfs", "Btrfs filesystem"},
+		{"xfs", "XFS filesystem"},
+		{"vfat", "FAT filesystem"},
+		{"swap", "swap space"},
+	}
+
+	compressions = []value{
+		{"gzip", "gzip-compressed contents"},
+		{"bzip2", "bzip2-compressed contents"},
+		{"xz", "xz-compressed contents"},
+		{"zstd", "Zstandard-compressed contents"},
+	}
+
+	// values offered for fields by their path
+	fieldValues = map[string][]value{
+		"storage.disks.partitions.typeGuid":  partitionTypes,
+		"storage.filesystems.mount.format":   filesystemFormats,
+		"storage.files.contents.compression": compressions,
+	}
+)
+
+// cursor describes where in a config a position is.
+type cursor struct {
+	// path is the path of the object whose keys are at the position if key
+	// is set, or of the field whose value is at the position otherwise. List
+	// indices are left out.
+	path []string
+	key  bool
+	// quoted is set if the position is inside a string, in which case word
+	// holds the whole string.
+	quoted bool
+	word   string
+}
+
+type frame struct {
+	object bool
+	// key is the last key read in an object, and colon is set once it's
+	// followed by a colon, i.e. when a value is expected
+	key   string
+	colon bool
+}
+
+// locate finds where the byte offset is in the JSON text. The text is
+// usually in the middle of being edited, so rather than parsing it, only
+// strings and the punctuation between them are followed.
+func locate(text []byte, offset int) cursor {
+	stack := []frame{}
+	top := func() *frame {
+		if len(stack) == 0 {
+			return &frame{}
+		}
+		return &stack[len(stack)-1]
+	}
+	c := cursor{}
+	for i := 0; i < offset && i < len(text); i++ {
+		switch text[i] {
+		case '"':
+			end := i + 1
+			for end < len(text) && text[end] != '"' && text[end] != '\n' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end > len(text) {
+				end = len(text)
+			}
+			// strings left unterminated while typing end at the line
+			var s string
+			if end == len(text) || text[end] != '"' || json.Unmarshal(text[i:end+1], &s) != nil {
+				s = string(text[i+1 : end])
+			}
+			if offset <= end {
+				c.quoted = true
+				c.word = s
+				i = len(text)
+				break
+			}
+			if t := top(); t.object && !t.colon {
+				t.key = s
+			}
+			i = end
+		case '{':
+			stack = append(stack, frame{object: true})
+		case '[':
+			stack = append(stack, frame{})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ':':
+			top().colon = true
+		case ',':
+			if t := top(); t.object {
+				t.key = ""
+				t.colon = false
+			}
+		}
+	}
+
+	for i, f := range stack {
+		if !f.object {
+			continue
+		}
+		if i == len(stack)-1 && !f.colon {
+			c.key = true
+			break
+		}
+		c.path = append(c.path, f.key)
+	}
+	if len(stack) == 0 {
+		c.key = true
+	}
+	return c
+}
+
+// field returns the path of the field at the cursor, if any.
+func (c cursor) field() (string, bool) {
+	if c.key {
+		if c.word == "" {
+			return "", false
+		}
+		return strings.Join(append(append([]string{}, c.path...), c.word), "."), true
+	}
+	if len(c.path) == 0 {
+		return "", false
+	}
+	return strings.Join(c.path, "."), true
+}
+
+// hover returns the description of the field at the cursor.
+func (c cursor) hover() (hover, bool) {
+	path, ok := c.field()
+	if !ok {
+		return hover{}, false
+	}
+	doc, ok := fieldDocs[path]
+	if !ok {
+		return hover{}, false
+	}
+	name := path[strings.LastIndex(path, ".")+1:]
+	return hover{Contents: markupContent{
+		Kind:  markupKindMarkdown,
+		Value: fmt.Sprintf("**%s** (%s)\n\n%s", name, doc.Type, doc.Description),
+	}}, true
+}
+
+// completions returns the keys of the object, or the known values of the
+// field, at the cursor.
+func (c cursor) completions() []completionItem {
+	items := []completionItem{}
+	if c.key {
+		t, ok := fieldType(c.path)
+		if !ok || t.Kind() != reflect.Struct {
+			return items
+		}
+		for _, f := range fields(t) {
+			name := jsonName(f)
+			path := strings.Join(append(append([]string{}, c.path...), name), ".")
+			item := completionItem{
+				Label:      name,
+				Kind:       completionKindProperty,
+				InsertText: c.quote(name),
+			}
+			if doc, ok := fieldDocs[path]; ok {
+				item.Detail = doc.Type
+				item.Documentation = &markupContent{Kind: markupKindMarkdown, Value: doc.Description}
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+
+	if t, ok := fieldType(c.path); ok && t.Kind() == reflect.Bool && !c.quoted {
+		return append(items,
+			completionItem{Label: "true", Kind: completionKindValue},
+			completionItem{Label: "false", Kind: completionKindValue})
+	}
+	for _, v := range fieldValues[strings.Join(c.path, ".")] {
+		items = append(items, completionItem{
+			Label:      v.Value,
+			Kind:       completionKindValue,
+			Detail:     v.Description,
+			InsertText: c.quote(v.Value),
+		})
+	}
+	return items
+}
+
+// quote quotes s unless the cursor is already in a string.
+func (c cursor) quote(s string) string {
+	if c.quoted {
+		return s
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// fieldType returns the type of the field at path in a config, with pointers
+// and lists dereferenced.
+func fieldType(path []string) (reflect.Type, bool) {
+	t := reflect.TypeOf(types.Config{})
+	for _, name := range path {
+		found := false
+		for _, f := range fields(t) {
+			if jsonName(f) == name {
+				t = f.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+	}
+	return t, true
+}
+
+// fields returns the fields of the struct t, including those of embedded
+// structs.
+func fields(t reflect.Type) []reflect.StructField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	ret := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			ret = append(ret, fields(f.Type)...)
+		} else if jsonName(f) != "" {
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+// atCursor splits a config containing a "|" at the cursor into the config
+// without it and the offset of the cursor.
+func atCursor(config string) ([]byte, int) {
+	return []byte(strings.Replace(config, "|", "", 1)), strings.Index(config, "|")
+}
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		in  string
+		out cursor
+	}{
+		{
+			in:  `|`,
+			out: cursor{key: true},
+		},
+		{
+			in:  `{"ignition": {"version": "2.2.0-experimental"}, |}`,
+			out: cursor{key: true},
+		},
+		{
+			in:  `{"storage": {"files": [{"path": "/a"}, {"con|`,
+			out: cursor{path: []string{"storage", "files"}, key: true, quoted: true, word: "con"},
+		},
+		{
+			in:  `{"storage": {"files": [{"contents": {"sou|rce": ""}}]}}`,
+			out: cursor{path: []string{"storage", "files", "contents"}, key: true, quoted: true, word: "source"},
+		},
+		{
+			in:  `{"storage": {"filesystems": [{"mount": {"format": |`,
+			out: cursor{path: []string{"storage", "filesystems", "mount", "format"}},
+		},
+		{
+			in:  `{"storage": {"disks": [{"partitions": [{"typeGuid": "0F|C6"}]}]}}`,
+			out: cursor{path: []string{"storage", "disks", "partitions", "typeGuid"}, quoted: true, word: "0FC6"},
+		},
+		{
+			in:  `{"passwd": {"users": [{"name": "a\"b", "sshAuthorizedKeys": ["x", |`,
+			out: cursor{path: []string{"passwd", "users", "sshAuthorizedKeys"}},
+		},
+		{
+			in: `{
+  "storage": {
+    "files": [{
+      "pa|
+`,
+			out: cursor{path: []string{"storage", "files"}, key: true, quoted: true, word: "pa"},
+		},
+	}
+
+	for i, test := range tests {
+		out := locate(atCursor(test.in))
+		if !reflect.DeepEqual(test.out, out) {
+			t.Errorf("#%d: bad cursor: want %+v, got %+v", i, test.out, out)
+		}
+	}
+}
+
+func labels(items []completionItem) []string {
+	ret := []string{}
+	for _, i := range items {
+		ret = append(ret, i.Label)
+	}
+	return ret
+}
+
+func TestCompletions(t *testing.T) {
+	tests := []struct {
+		in  cursor
+		out []string
+	}{
+		{
+			in:  cursor{key: true},
+			out: []string{"ignition", "networkd", "passwd", "storage", "systemd"},
+		},
+		{
+			in:  cursor{path: []string{"storage", "files", "contents"}, key: true},
+			out: []string{"compression", "httpHeaders", "source", "verification"},
+		},
+		{
+			in:  cursor{path: []string{"storage", "links"}, key: true},
+			out: []string{"filesystem", "group", "overwrite", "path", "user", "hard", "target"},
+		},
+		{
+			in:  cursor{path: []string{"storage", "filesystems", "mount", "format"}},
+			out: []string{"ext4", "btrfs", "xfs", "vfat", "swap"},
+		},
+		{
+			in:  cursor{path: []string{"systemd", "units", "mask"}},
+			out: []string{"true", "false"},
+		},
+		{
+			in:  cursor{path: []string{"storage", "files", "path"}},
+			out: []string{},
+		},
+		{
+			in:  cursor{path: []string{"storage", "bogus"}, key: true},
+			out: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		out := labels(test.in.completions())
+		if !reflect.DeepEqual(test.out, out) {
+			t.Errorf("#%d: bad completions: want %v, got %v", i, test.out, out)
+		}
+	}
+}
+
+func TestCompletionsQuoted(t *testing.T) {
+	c := cursor{path: []string{"storage", "disks", "partitions", "typeGuid"}}
+	if text := c.completions()[0].InsertText; text != `"0FC63DAF-8483-4772-8E79-3D69D8477DE4"` {
+		t.Errorf("bad insert text outside a string: %s", text)
+	}
+	c.quoted = true
+	if text := c.completions()[0].InsertText; text != `0FC63DAF-8483-4772-8E79-3D69D8477DE4` {
+		t.Errorf("bad insert text inside a string: %s", text)
+	}
+}
+
+func TestFieldValuesValid(t *testing.T) {
+	for _, v := range partitionTypes {
+		if r := (types.Partition{TypeGUID: v.Value}).ValidateTypeGUID(); len(r.Entries) != 0 {
+			t.Errorf("partition type %q isn't valid: %v", v.Value, r)
+		}
+	}
+	for _, v := range filesystemFormats {
+		if r := (types.Mount{Format: v.Value}).Validate(); len(r.Entries) != 0 {
+			t.Errorf("filesystem format %q isn't valid: %v", v.Value, r)
+		}
+	}
+	for _, v := range compressions {
+		if r := (types.FileContents{Compression: v.Value}).ValidateCompression(); len(r.Entries) != 0 {
+			t.Errorf("compression %q isn't valid: %v", v.Value, r)
+		}
+	}
+}
+
+func TestHover(t *testing.T) {
+	tests := []struct {
+		in  cursor
+		out string
+	}{
+		{
+			in:  cursor{path: []string{"storage", "files"}, key: true, quoted: true, word: "mode"},
+			out: "**mode** (integer)\n\nthe file's permission mode. Note that the mode must be properly specified as a **decimal** value (i.e. 0644 -> 420).",
+		},
+		{
+			in:  cursor{path: []string{"passwd", "users", "name"}},
+			out: "**name** (string)\n\nthe username for the account.",
+		},
+	}
+
+	for i, test := range tests {
+		h, ok := test.in.hover()
+		if !ok {
+			t.Errorf("#%d: no hover", i)
+			continue
+		}
+		if h.Contents.Value != test.out {
+			t.Errorf("#%d: bad hover: want %q, got %q", i, test.out, h.Contents.Value)
+		}
+	}
+
+	if _, ok := (cursor{path: []string{"storage"}, key: true}).hover(); ok {
+		t.Errorf("hover between keys")
+	}
+}
+
+// TestFieldDocs checks that every field of the config is described in the
+// spec, so that it can be shown on hover.
+func TestFieldDocs(t *testing.T) {
+	var check func(path []string, typ reflect.Type)
+	check = func(path []string, typ reflect.Type) {
+		for _, f := range fields(typ) {
+			p := append(append([]string{}, path...), jsonName(f))
+			if _, ok := fieldDocs[strings.Join(p, ".")]; !ok {
+				t.Errorf("field %s isn't described", strings.Join(p, "."))
+			}
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+				ft = ft.Elem()
+			}
+			check(p, ft)
+		}
+	}
+	check(nil, reflect.TypeOf(types.Config{}))
+}
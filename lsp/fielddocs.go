@@ -0,0 +1,184 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// generated by "go run internal/util/tools/fielddocs/fielddocs.go" -- DO NOT EDIT
+
+package main
+
+// fieldDocs maps the path of each field of the config, with list indices left
+// out, to its type and description in the spec.
+var fieldDocs = map[string]fieldDoc{
+	"ignition":                                                            {"object", "metadata about the configuration itself."},
+	"ignition.version":                                                    {"string", "the semantic version number of the spec. The spec version must be compatible with the latest version (`2.2.0-experimental`). Compatibility requires the major versions to match and the spec version be less than or equal to the latest version. `-experimental` versions compare less than the final version with the same number, and previous experimental versions are not accepted."},
+	"ignition.config":                                                     {"objects", "options related to the configuration."},
+	"ignition.config.append":                                              {"list of objects", "a list of the configs to be appended to the current config."},
+	"ignition.config.append.source":                                       {"string", "the URL of the config. Supported schemes are `http`, `https`, `s3`, `tftp`, and `data`. Note: When using `http`, it is advisable to use the verification option to ensure the contents haven't been modified."},
+	"ignition.config.append.mirrors":                                      {"list of strings", "alternate URLs serving the same config, tried in order if the source can't be fetched or doesn't match the verification hash. Supported schemes are the same as for the source. A verification hash is required when mirrors are specified."},
+	"ignition.config.append.httpHeaders":                                  {"list of objects", "additional HTTP headers to send when fetching the config, and its signature, over `http` or `https`. Headers managed by the HTTP client, such as `Host` or `Content-Length`, can't be set, and each header can only be given once."},
+	"ignition.config.append.httpHeaders.name":                             {"string", "the header name."},
+	"ignition.config.append.httpHeaders.value":                            {"string", "the header value. If unset, the header is removed, which can be used to omit the default `User-Agent` header."},
+	"ignition.config.append.verification":                                 {"object", "options related to the verification of the config."},
+	"ignition.config.append.verification.hash":                            {"string", "the hash of the config, in the form `<type>-<value>` where type is `sha256`, `sha384`, or `sha512`."},
+	"ignition.config.append.verification.signature":                       {"string", "the URL of a detached signature of the config, checked against the system's trusted keys. Supported schemes are the same as for the source."},
+	"ignition.config.replace":                                             {"object", "the config that will replace the current."},
+	"ignition.config.replace.source":                                      {"string", "the URL of the config. Supported schemes are `http`, `https`, `s3`, `tftp`, and `data`. Note: When using `http`, it is advisable to use the verification option to ensure the contents haven't been modified."},
+	"ignition.config.replace.mirrors":                                     {"list of strings", "alternate URLs serving the same config, tried in order if the source can't be fetched or doesn't match the verification hash. Supported schemes are the same as for the source. A verification hash is required when mirrors are specified."},
+	"ignition.config.replace.httpHeaders":                                 {"list of objects", "additional HTTP headers to send when fetching the config, and its signature, over `http` or `https`. Headers managed by the HTTP client, such as `Host` or `Content-Length`, can't be set, and each header can only be given once."},
+	"ignition.config.replace.httpHeaders.name":                            {"string", "the header name."},
+	"ignition.config.replace.httpHeaders.value":                           {"string", "the header value. If unset, the header is removed, which can be used to omit the default `User-Agent` header."},
+	"ignition.config.replace.verification":                                {"object", "options related to the verification of the config."},
+	"ignition.config.replace.verification.hash":                           {"string", "the hash of the config, in the form `<type>-<value>` where type is `sha256`, `sha384`, or `sha512`."},
+	"ignition.config.replace.verification.signature":                      {"string", "the URL of a detached signature of the config, checked against the system's trusted keys. Supported schemes are the same as for the source."},
+	"ignition.timeouts":                                                   {"object", "options relating to `http` timeouts when fetching files over `http` or `https`."},
+	"ignition.timeouts.httpResponseHeaders":                               {"integer", "the time to wait (in seconds) for the server's response headers (but not the body) after making a request. 0 indicates no timeout. Default is 10 seconds."},
+	"ignition.timeouts.httpTotal":                                         {"integer", "the time limit (in seconds) for the operation (connection, request, and response), including retries. 0 indicates no timeout. Default is 0."},
+	"ignition.security":                                                   {"object", "options relating to network security."},
+	"ignition.security.tls":                                               {"object", "options relating to TLS when fetching resources over `https`."},
+	"ignition.security.tls.certificateAuthorities":                        {"list of objects", "the list of additional certificate authorities (in addition to the system authorities) to be used for TLS verification when fetching over `https`."},
+	"ignition.security.tls.certificateAuthorities.source":                 {"string", "the URL of the certificate (in PEM format). Supported schemes are `http`, `https`, `s3`, `tftp`, and `data`. Note: When using `http`, it is advisable to use the verification option to ensure the contents haven't been modified."},
+	"ignition.security.tls.certificateAuthorities.httpHeaders":            {"list of objects", "additional HTTP headers to send when fetching the certificate, and its signature, over `http` or `https`. Headers managed by the HTTP client, such as `Host` or `Content-Length`, can't be set, and each header can only be given once."},
+	"ignition.security.tls.certificateAuthorities.httpHeaders.name":       {"string", "the header name."},
+	"ignition.security.tls.certificateAuthorities.httpHeaders.value":      {"string", "the header value. If unset, the header is removed, which can be used to omit the default `User-Agent` header."},
+	"ignition.security.tls.certificateAuthorities.verification":           {"object", "options related to the verification of the certificate."},
+	"ignition.security.tls.certificateAuthorities.verification.hash":      {"string", "the hash of the certificate, in the form `<type>-<value>` where type is `sha256`, `sha384`, or `sha512`."},
+	"ignition.security.tls.certificateAuthorities.verification.signature": {"string", "the URL of a detached signature of the certificate, checked against the system's trusted keys. Supported schemes are the same as for the source."},
+	"ignition.proxy":                                                      {"object", "options relating to the HTTP proxies used when fetching resources over `http` or `https`. Settings left unset fall back to the proxy kernel parameters and environment variables."},
+	"ignition.proxy.httpProxy":                                            {"string", "the URL of the proxy for `http` requests, e.g. `http://proxy.example.com:3128`."},
+	"ignition.proxy.httpsProxy":                                           {"string", "the URL of the proxy for `https` requests."},
+	"ignition.proxy.noProxy":                                              {"list of strings", "the hosts which are reached without a proxy. An entry matches a host name and its subdomains (a leading `.` is ignored), an IP address, or a CIDR range, optionally with a port; `*` matches every host."},
+	"storage":                                                             {"object", "describes the desired state of the system's storage devices."},
+	"storage.disks":                                                       {"list of objects", "the list of disks to be configured and their options."},
+	"storage.disks.device":                                                {"string", "the absolute path to the device. Devices are typically referenced by the `/dev/disk/by-*` symlinks."},
+	"storage.disks.wipeTable":                                             {"boolean", "whether or not the partition tables shall be wiped. When true, the partition tables are erased before any further manipulation. Otherwise, the existing entries are left intact."},
+	"storage.disks.partitions":                                            {"list of objects", "the list of partitions and their configuration for this particular disk."},
+	"storage.disks.partitions.label":                                      {"string", "the PARTLABEL for the partition."},
+	"storage.disks.partitions.number":                                     {"integer", "the partition number, which dictates it's position in the partition table (one-indexed). If zero, use the next available partition slot."},
+	"storage.disks.partitions.size":                                       {"integer", "the size of the partition (in device logical sectors, 512 or 4096 bytes). If zero, the partition will be made as large as possible."},
+	"storage.disks.partitions.start":                                      {"integer", "the start of the partition (in device logical sectors). If zero, the partition will be positioned at the start of the largest block available."},
+	"storage.disks.partitions.typeGuid":                                   {"string", "the GPT partition type GUID. If omitted, the default will be 0FC63DAF-8483-4772-8E79-3D69D8477DE4 (Linux filesystem data)."},
+	"storage.disks.partitions.guid":                                       {"string", "the GPT unique partition GUID."},
+	"storage.raid":                                                        {"list of objects", "the list of RAID arrays to be configured."},
+	"storage.raid.name":                                                   {"string", "the name to use for the resulting md device."},
+	"storage.raid.level":                                                  {"string", "the redundancy level of the array (e.g. linear, raid1, raid5, etc.)."},
+	"storage.raid.devices":                                                {"list of strings", "the list of devices (referenced by their absolute path) in the array."},
+	"storage.raid.spares":                                                 {"integer", "the number of spares (if applicable) in the array."},
+	"storage.raid.options":                                                {"list of strings", "any additional options to be passed to mdadm."},
+	"storage.filesystems":                                                 {"list of objects", "the list of filesystems to be configured and/or used in the \"files\" section. Either \"mount\" or \"path\" needs to be specified."},
+	"storage.filesystems.name":                                            {"string", "the identifier for the filesystem, internal to Ignition. This is only required if the filesystem needs to be referenced in the \"files\" section."},
+	"storage.filesystems.mount":                                           {"object", "contains the set of mount and formatting options for the filesystem. A non-null entry indicates that the filesystem should be mounted before it is used by Ignition."},
+	"storage.filesystems.mount.device":                                    {"string", "the absolute path to the device. Devices are typically referenced by the `/dev/disk/by-*` symlinks."},
+	"storage.filesystems.mount.format":                                    {"string", "the filesystem format (ext4, btrfs, xfs, vfat, or swap)."},
+	"storage.filesystems.mount.wipeFilesystem":                            {"boolean", "whether or not to wipe the device before filesystem creation, see [the documentation on filesystems](operator-notes.md#filesystem-reuse-semantics) for more information."},
+	"storage.filesystems.mount.label":                                     {"string", "the label of the filesystem."},
+	"storage.filesystems.mount.uuid":                                      {"string", "the uuid of the filesystem."},
+	"storage.filesystems.mount.options":                                   {"list of strings", "any additional options to be passed to the format-specific mkfs utility."},
+	"storage.filesystems.mount.mountPath":                                 {"string", "the absolute path, relative to the root, at which the \"mount\" stage mounts the filesystem. Filesystems are mounted in order of path depth and are unmounted in reverse order by the \"umount\" stage. The \"files\" stage writes into the filesystem at this path, and fails if nothing is mounted there. Cannot be used with swap."},
+	"storage.filesystems.mount.mountOptions":                              {"list of strings", "any options to be used when mounting the filesystem at its mountPath (e.g. `subvol=var`)."},
+	"storage.filesystems.mount.create":                                    {"object, DEPRECATED", "contains the set of options to be used when creating the filesystem."},
+	"storage.filesystems.mount.create.force":                              {"boolean, DEPRECATED", "whether or not the create operation shall overwrite an existing filesystem."},
+	"storage.filesystems.mount.create.options":                            {"list of strings, DEPRECATED", "any additional options to be passed to the format-specific mkfs utility."},
+	"storage.filesystems.path":                                            {"string", "the mount-point of the filesystem. A non-null entry indicates that the filesystem has already been mounted by the system at the specified path. This is really only useful for \"/sysroot\"."},
+	"storage.files":                                                       {"list of objects", "the list of files to be written."},
+	"storage.files.filesystem":                                            {"string", "the internal identifier of the filesystem in which to write the file. This matches the last filesystem with the given identifier."},
+	"storage.files.path":                                                  {"string", "the absolute path to the file."},
+	"storage.files.overwrite":                                             {"boolean", "whether to delete preexisting nodes at the path. Defaults to true."},
+	"storage.files.append":                                                {"boolean", "whether to append to the specified file. Creates a new file if nothing exists at the path. Cannot be set if overwrite is set to true."},
+	"storage.files.contents":                                              {"object", "options related to the contents of the file."},
+	"storage.files.contents.compression":                                  {"string", "the type of compression used on the contents (null, `gzip`, `bzip2`, `xz`, or `zstd`). `xz` and `zstd` contents are decompressed with the `xz` and `zstd` tools, which must be in the initramfs. The verification hash is of the decompressed contents."},
+	"storage.files.contents.source":                                       {"string", "the URL of the file contents. Supported schemes are `http`, `https`, `tftp`, `s3`, and `data`. When using `http`, it is advisable to use the verification option to ensure the contents haven't been modified."},
+	"storage.files.contents.httpHeaders":                                  {"list of objects", "additional HTTP headers to send when fetching the file contents, and its signature, over `http` or `https`. Headers managed by the HTTP client, such as `Host` or `Content-Length`, can't be set, and each header can only be given once."},
+	"storage.files.contents.httpHeaders.name":                             {"string", "the header name."},
+	"storage.files.contents.httpHeaders.value":                            {"string", "the header value. If unset, the header is removed, which can be used to omit the default `User-Agent` header."},
+	"storage.files.contents.verification":                                 {"object", "options related to the verification of the file contents."},
+	"storage.files.contents.verification.hash":                            {"string", "the hash of the config, in the form `<type>-<value>` where type is `sha256`, `sha384`, or `sha512`."},
+	"storage.files.contents.verification.signature":                       {"string", "the URL of a detached signature of the file contents, checked against the system's trusted keys. Signatures of compressed contents cover the decompressed contents. Supported schemes are the same as for the source."},
+	"storage.files.mode":                                                  {"integer", "the file's permission mode. Note that the mode must be properly specified as a **decimal** value (i.e. 0644 -> 420)."},
+	"storage.files.user":                                                  {"object", "specifies the file's owner."},
+	"storage.files.user.id":                                               {"integer", "the user ID of the owner."},
+	"storage.files.user.name":                                             {"string", "the user name of the owner."},
+	"storage.files.group":                                                 {"object", "specifies the group of the owner."},
+	"storage.files.group.id":                                              {"integer", "the group ID of the owner."},
+	"storage.files.group.name":                                            {"string", "the group name of the owner."},
+	"storage.directories":                                                 {"list of objects", "the list of directories to be created."},
+	"storage.directories.filesystem":                                      {"string", "the internal identifier of the filesystem in which to create the directory. This matches the last filesystem with the given identifier."},
+	"storage.directories.path":                                            {"string", "the absolute path to the directory."},
+	"storage.directories.overwrite":                                       {"boolean", "whether to delete preexisting nodes at the path."},
+	"storage.directories.mode":                                            {"integer", "the directory's permission mode. Note that the mode must be properly specified as a **decimal** value (i.e. 0755 -> 493)."},
+	"storage.directories.user":                                            {"object", "specifies the directory's owner."},
+	"storage.directories.user.id":                                         {"integer", "the user ID of the owner."},
+	"storage.directories.user.name":                                       {"string", "the user name of the owner."},
+	"storage.directories.group":                                           {"object", "specifies the group of the owner."},
+	"storage.directories.group.id":                                        {"integer", "the group ID of the owner."},
+	"storage.directories.group.name":                                      {"string", "the group name of the owner."},
+	"storage.links":                                                       {"list of objects", "the list of links to be created"},
+	"storage.links.filesystem":                                            {"string", "the internal identifier of the filesystem in which to write the link. This matches the last filesystem with the given identifier."},
+	"storage.links.path":                                                  {"string", "the absolute path to the link"},
+	"storage.links.overwrite":                                             {"boolean", "whether to delete preexisting nodes at the path."},
+	"storage.links.user":                                                  {"object", "specifies the symbolic link's owner."},
+	"storage.links.user.id":                                               {"integer", "the user ID of the owner."},
+	"storage.links.user.name":                                             {"string", "the user name of the owner."},
+	"storage.links.group":                                                 {"object", "specifies the group of the owner."},
+	"storage.links.group.id":                                              {"integer", "the group ID of the owner."},
+	"storage.links.group.name":                                            {"string", "the group name of the owner."},
+	"storage.links.target":                                                {"string", "the target path of the link"},
+	"storage.links.hard":                                                  {"boolean", "a symbolic link is created if this is false, a hard one if this is true."},
+	"systemd":                                                             {"object", "describes the desired state of the systemd units."},
+	"systemd.units":                                                       {"list of objects", "the list of systemd units."},
+	"systemd.units.name":                                                  {"string", "the name of the unit. This must be suffixed with a valid unit type (e.g. \"thing.service\")."},
+	"systemd.units.enable":                                                {"boolean, DEPRECATED", "whether or not the service shall be enabled. When true, the service is enabled. In order for this to have any effect, the unit must have an install section."},
+	"systemd.units.enabled":                                               {"boolean", "whether or not the service shall be enabled. When true, the service is enabled. When false, the service is disabled. When omitted, the service is unmodified. In order for this to have any effect, the unit must have an install section."},
+	"systemd.units.mask":                                                  {"boolean", "whether or not the service shall be masked. When true, the service is masked by symlinking it to `/dev/null`."},
+	"systemd.units.contents":                                              {"string", "the contents of the unit."},
+	"systemd.units.dropins":                                               {"list of objects", "the list of drop-ins for the unit."},
+	"systemd.units.dropins.name":                                          {"string", "the name of the drop-in. This must be suffixed with \".conf\"."},
+	"systemd.units.dropins.contents":                                      {"string", "the contents of the drop-in."},
+	"networkd":                                                            {"object", "describes the desired state of the networkd files."},
+	"networkd.units":                                                      {"list of objects", "the list of networkd files."},
+	"networkd.units.name":                                                 {"string", "the name of the file. This must be suffixed with a valid unit type (e.g. \"00-eth0.network\")."},
+	"networkd.units.contents":                                             {"string", "the contents of the networkd file."},
+	"networkd.units.dropins":                                              {"list of objects", "the list of drop-ins for the unit."},
+	"networkd.units.dropins.name":                                         {"string", "the name of the drop-in. This must be suffixed with \".conf\"."},
+	"networkd.units.dropins.contents":                                     {"string", "the contents of the drop-in."},
+	"passwd":                                                              {"object", "describes the desired additions to the passwd database."},
+	"passwd.users":                                                        {"list of objects", "the list of accounts that shall exist."},
+	"passwd.users.name":                                                   {"string", "the username for the account."},
+	"passwd.users.passwordHash":                                           {"string", "the encrypted password for the account."},
+	"passwd.users.sshAuthorizedKeys":                                      {"list of strings", "a list of SSH keys to be added to the user's authorized_keys."},
+	"passwd.users.uid":                                                    {"integer", "the user ID of the account."},
+	"passwd.users.gecos":                                                  {"string", "the GECOS field of the account."},
+	"passwd.users.homeDir":                                                {"string", "the home directory of the account."},
+	"passwd.users.noCreateHome":                                           {"boolean", "whether or not to create the user's home directory. This only has an effect if the account doesn't exist yet."},
+	"passwd.users.primaryGroup":                                           {"string", "the name of the primary group of the account."},
+	"passwd.users.groups":                                                 {"list of strings", "the list of supplementary groups of the account."},
+	"passwd.users.noUserGroup":                                            {"boolean", "whether or not to create a group with the same name as the user. This only has an effect if the account doesn't exist yet."},
+	"passwd.users.noLogInit":                                              {"boolean", "whether or not to add the user to the lastlog and faillog databases. This only has an effect if the account doesn't exist yet."},
+	"passwd.users.shell":                                                  {"string", "the login shell of the new account."},
+	"passwd.users.system":                                                 {"bool", "whether or not to make the account a system account. This only has an effect if the account doesn't exist yet."},
+	"passwd.users.create":                                                 {"object, DEPRECATED", "contains the set of options to be used when creating the user. A non-null entry indicates that the user account shall be created. This object has been marked for deprecation, please use the **_users_** level fields instead."},
+	"passwd.users.create.uid":                                             {"integer", "the user ID of the new account."},
+	"passwd.users.create.gecos":                                           {"string", "the GECOS field of the new account."},
+	"passwd.users.create.homeDir":                                         {"string", "the home directory of the new account."},
+	"passwd.users.create.noCreateHome":                                    {"boolean", "whether or not to create the user's home directory."},
+	"passwd.users.create.primaryGroup":                                    {"string", "the name or ID of the primary group of the new account."},
+	"passwd.users.create.groups":                                          {"list of strings", "the list of supplementary groups of the new account."},
+	"passwd.users.create.noUserGroup":                                     {"boolean", "whether or not to create a group with the same name as the user."},
+	"passwd.users.create.noLogInit":                                       {"boolean", "whether or not to add the user to the lastlog and faillog databases."},
+	"passwd.users.create.shell":                                           {"string", "the login shell of the new account."},
+	"passwd.users.create.system":                                          {"bool", "whether or not to make the account a system account."},
+	"passwd.groups":                                                       {"list of objects", "the list of groups to be added."},
+	"passwd.groups.name":                                                  {"string", "the name of the group."},
+	"passwd.groups.gid":                                                   {"integer", "the group ID of the new group."},
+	"passwd.groups.passwordHash":                                          {"string", "the encrypted password of the new group."},
+	"passwd.groups.system":                                                {"bool", "whether or not the group should be a system group. This only has an effect if the group doesn't exist yet."},
+}
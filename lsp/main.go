@@ -0,0 +1,69 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/coreos/ignition/internal/version"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagVersion bool
+	rootCmd     = &cobra.Command{
+		Use:   "ignition-lsp",
+		Short: "ignition-lsp is a language server for Ignition configs, speaking the Language Server Protocol over stdin and stdout",
+		Args:  cobra.NoArgs,
+		Run:   runIgnLsp,
+	}
+)
+
+func main() {
+	rootCmd.Flags().BoolVar(&flagVersion, "version", false, "print the version of ignition-lsp")
+	rootCmd.Execute()
+}
+
+func stdout(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stdout, strings.TrimSpace(format)+"\n", a...)
+}
+
+func stderr(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, strings.TrimSpace(format)+"\n", a...)
+}
+
+func die(format string, a ...interface{}) {
+	stderr(format, a...)
+	os.Exit(1)
+}
+
+func runIgnLsp(cmd *cobra.Command, args []string) {
+	if flagVersion {
+		stdout(version.String)
+		return
+	}
+	shutdown, err := newServer(os.Stdin, os.Stdout).serve()
+	if err != nil {
+		die("%v", err)
+	}
+	// the protocol asks for a failure exit code if the client exits without
+	// shutting the server down first
+	if !shutdown {
+		os.Exit(1)
+	}
+}
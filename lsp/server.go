@@ -0,0 +1,270 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/validate/report"
+	"github.com/coreos/ignition/internal/version"
+)
+
+// server is a language server for Ignition configs. Documents are synced in
+// full on every change and revalidated, so diagnostics are published as the
+// config is edited.
+type server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string][]byte
+	shutdown  bool
+}
+
+func newServer(in io.Reader, out io.Writer) *server {
+	return &server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: map[string][]byte{},
+	}
+}
+
+// serve handles messages until the client asks the server to exit, and
+// returns whether the client asked it to shut down first.
+func (s *server) serve() (bool, error) {
+	for {
+		body, err := readMessage(s.in)
+		if err != nil {
+			return false, err
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return s.shutdown, nil
+		}
+		if err := s.handle(msg); err != nil {
+			return false, err
+		}
+	}
+}
+
+func (s *server) handle(msg message) error {
+	if msg.ID == nil {
+		return s.notify(msg.Method, msg.Params)
+	}
+	result, rerr := s.call(msg.Method, msg.Params)
+	if rerr != nil {
+		return s.replyError(msg.ID, rerr.Code, rerr.Message)
+	}
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: msg.ID, Result: result})
+}
+
+func (s *server) replyError(id *json.RawMessage, code int, msg string) error {
+	return writeMessage(s.out, errorResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   responseError{Code: code, Message: msg},
+	})
+}
+
+// notify handles a notification. Notifications can't be answered, so
+// malformed and unknown ones are ignored.
+func (s *server) notify(method string, params json.RawMessage) error {
+	switch method {
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil
+		}
+		s.documents[p.TextDocument.URI] = []byte(p.TextDocument.Text)
+		return s.publishDiagnostics(p.TextDocument.URI)
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(params, &p); err != nil || len(p.ContentChanges) == 0 {
+			return nil
+		}
+		// only full syncs are offered, so the last change holds the whole text
+		s.documents[p.TextDocument.URI] = []byte(p.ContentChanges[len(p.ContentChanges)-1].Text)
+		return s.publishDiagnostics(p.TextDocument.URI)
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil
+		}
+		delete(s.documents, p.TextDocument.URI)
+		return writeMessage(s.out, notification{
+			JSONRPC: "2.0",
+			Method:  "textDocument/publishDiagnostics",
+			Params:  publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []diagnostic{}},
+		})
+	}
+	return nil
+}
+
+// call handles a request and returns its result.
+func (s *server) call(method string, params json.RawMessage) (interface{}, *responseError) {
+	switch method {
+	case "initialize":
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncFull,
+				CompletionProvider: completionOptions{
+					TriggerCharacters: []string{`"`},
+				},
+				HoverProvider: true,
+			},
+			ServerInfo: serverInfo{Name: "ignition-lsp", Version: version.Raw},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/completion", "textDocument/hover":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		text, ok := s.documents[p.TextDocument.URI]
+		if !ok {
+			return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("document %q isn't open", p.TextDocument.URI)}
+		}
+		c := locate(text, offsetAt(text, p.Position))
+		if method == "textDocument/hover" {
+			if h, ok := c.hover(); ok {
+				return h, nil
+			}
+			return nil, nil
+		}
+		return c.completions(), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q is not supported", method)}
+}
+
+func (s *server) publishDiagnostics(uri string) error {
+	return writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diagnose(s.documents[uri])},
+	})
+}
+
+// diagnose validates the config in text and converts the entries of the
+// report to diagnostics. Entries without a position, such as those for
+// configs of older spec versions, are put at the start of the config.
+func diagnose(text []byte) []diagnostic {
+	_, r, err := config.Parse(text)
+	if err != nil && len(r.Entries) == 0 {
+		r = report.ReportFromError(err, report.EntryError)
+	}
+	diagnostics := []diagnostic{}
+	for _, e := range r.Entries {
+		d := diagnostic{
+			Range:    entryRange(text, e.Line, e.Column),
+			Severity: severityError,
+			Code:     e.Code,
+			Source:   "ignition",
+			Message:  e.Message,
+		}
+		switch e.Kind {
+		case report.EntryWarning, report.EntryDeprecated:
+			d.Severity = severityWarning
+		case report.EntryInfo:
+			d.Severity = severityInformation
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// entryRange returns the range covering the line of a report entry up to its
+// column. Entries point just past the end of the value they're about, and
+// configs usually have one value per line, so this highlights the key and
+// value at fault.
+func entryRange(text []byte, line, col int) textRange {
+	if line < 1 {
+		return textRange{}
+	}
+	start := 0
+	for l := 1; l < line && start < len(text); l++ {
+		if i := bytes.IndexByte(text[start:], '\n'); i >= 0 {
+			start += i + 1
+		} else {
+			start = len(text)
+		}
+	}
+	end := start + bytes.IndexByte(text[start:], '\n')
+	if end < start {
+		end = len(text)
+	}
+	at := start + col - 1
+	if at < start || at > end {
+		at = end
+	}
+	first := start
+	for first < at && (text[first] == ' ' || text[first] == '\t') {
+		first++
+	}
+	if first == at {
+		at = end
+	}
+	return textRange{Start: positionAt(text, first), End: positionAt(text, at)}
+}
+
+// offsetAt returns the byte offset of p in text. Characters are counted in
+// UTF-16 code units, as the protocol requires.
+func offsetAt(text []byte, p position) int {
+	offset := 0
+	for l := 0; l < p.Line; l++ {
+		i := bytes.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		offset += i + 1
+	}
+	for units := 0; units < p.Character && offset < len(text) && text[offset] != '\n'; {
+		r, size := utf8.DecodeRune(text[offset:])
+		units += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+// positionAt returns the position of the byte offset in text.
+func positionAt(text []byte, offset int) position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	p := position{}
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if text[i] == '\n' {
+			p.Line++
+			lineStart = i + 1
+		}
+	}
+	for _, r := range string(text[lineStart:offset]) {
+		p.Character += len(utf16.Encode([]rune{r}))
+	}
+	return p
+}
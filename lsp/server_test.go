@@ -0,0 +1,126 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	config := "{\n  \"ignition\": {\"version\": \"2.2.0-experimental\"},\n  \"storage\": {\"filesystems\": [{\"mount\": {\"device\": \"/dev/sda\", \"format\": \"ext5\"}}]}\n}"
+	var in bytes.Buffer
+	for _, m := range []interface{}{
+		map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": "file:///config.ign", "languageId": "json", "version": 1, "text": config},
+		}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "textDocument/completion", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": "file:///config.ign"},
+			"position":     map[string]interface{}{"line": 1, "character": 3},
+		}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 3, "method": "textDocument/definition", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 4, "method": "shutdown"},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "exit"},
+	} {
+		if err := writeMessage(&in, m); err != nil {
+			t.Fatalf("failed to write message: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	shutdown, err := newServer(&in, &out).serve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !shutdown {
+		t.Errorf("server wasn't shut down")
+	}
+
+	replies := []map[string]interface{}{}
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var reply map[string]interface{}
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatalf("bad reply %s: %v", body, err)
+		}
+		replies = append(replies, reply)
+	}
+	if len(replies) != 5 {
+		t.Fatalf("expected 5 replies, got %d: %v", len(replies), replies)
+	}
+
+	if caps := replies[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{}); caps["hoverProvider"] != true {
+		t.Errorf("hover isn't offered: %v", caps)
+	}
+
+	expected := []interface{}{map[string]interface{}{
+		"range": map[string]interface{}{
+			"start": map[string]interface{}{"line": 2.0, "character": 2.0},
+			"end":   map[string]interface{}{"line": 2.0, "character": 80.0},
+		},
+		"severity": 1.0,
+		"code":     "IGN-STORAGE-001",
+		"source":   "ignition",
+		"message":  "invalid filesystem format",
+	}}
+	if diagnostics := replies[1]["params"].(map[string]interface{})["diagnostics"]; !reflect.DeepEqual(expected, diagnostics) {
+		t.Errorf("bad diagnostics: want %v, got %v", expected, diagnostics)
+	}
+
+	if items := replies[2]["result"].([]interface{}); len(items) != 5 {
+		t.Errorf("expected the 5 top-level keys, got %v", items)
+	}
+
+	if e := replies[3]["error"].(map[string]interface{}); e["code"] != float64(codeMethodNotFound) {
+		t.Errorf("bad error for an unsupported method: %v", e)
+	}
+
+	if _, ok := replies[4]["result"]; !ok || replies[4]["result"] != nil {
+		t.Errorf("bad shutdown reply: %v", replies[4])
+	}
+}
+
+func TestPositions(t *testing.T) {
+	text := []byte("{\n  \"gecos\": \"Zoë 𝄞\",\n}")
+	tests := []struct {
+		pos    position
+		offset int
+	}{
+		{position{0, 0}, 0},
+		{position{1, 2}, 4},
+		{position{1, 16}, 19},
+		// 𝄞 is 4 bytes in UTF-8 but 2 units in UTF-16
+		{position{1, 18}, 23},
+		{position{2, 1}, 27},
+	}
+
+	for i, test := range tests {
+		if offset := offsetAt(text, test.pos); offset != test.offset {
+			t.Errorf("#%d: bad offset: want %d, got %d", i, test.offset, offset)
+		}
+		if pos := positionAt(text, test.offset); pos != test.pos {
+			t.Errorf("#%d: bad position: want %v, got %v", i, test.pos, pos)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+	"github.com/coreos/ignition/internal/util"
+
+	"github.com/spf13/cobra"
+	"github.com/vincent-petithory/dataurl"
+)
+
+func runBundle(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		os.Exit(1)
+	}
+	cfg, rpt, err := config.Parse(readConfig(args[0]))
+	printReport(stderr, rpt, args[0])
+	if rpt.IsFatal() {
+		os.Exit(1)
+	}
+	if err != nil {
+		die("couldn't parse config: %v", err)
+	}
+
+	logger := log.NewWithOps(log.Stderr{})
+	b := bundler{
+		fetcher:  resource.Fetcher{Logger: &logger, DefaultProxy: resource.ProxyFromEnvironment()},
+		compress: flagCompress,
+		merge:    flagMergeConfigs,
+		printReport: func(r report.Report, source string) {
+			printReport(stderr, r, source)
+		},
+	}
+	cfg, err = b.bundle(cfg)
+	if err != nil {
+		die("couldn't bundle config: %v", err)
+	}
+	cfg.Ignition.Version = types.MaxVersion.String()
+
+	out, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		die("couldn't marshal config: %v", err)
+	}
+	stdout("%s", out)
+}
+
+// bundler turns configs into ones which don't need the network, by
+// flattening the configs they reference into them and replacing remote
+// sources with data urls.
+type bundler struct {
+	fetcher  resource.Fetcher
+	compress bool
+	merge    bool
+	// printReport, if set, is called with the report of each referenced
+	// config and its source.
+	printReport func(r report.Report, source string)
+}
+
+// bundle returns cfg with the configs it references flattened into it, and
+// the contents of its files and its CAs rewritten as data urls. Hashes are
+// checked as everything is fetched. Signatures can't be checked without the
+// target's trusted keys, and those of flattened configs are lost, so the
+// bundle has to be signed again if its targets require signatures.
+func (b *bundler) bundle(cfg types.Config) (types.Config, error) {
+	if err := b.fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy); err != nil {
+		return types.Config{}, err
+	}
+	cfg, err := b.flatten(cfg)
+	if err != nil {
+		return types.Config{}, err
+	}
+	cfg.Ignition.Config = types.IgnitionConfig{}
+
+	if err := b.fetcher.RewriteCAsWithDataUrls(cfg.Ignition.Security.TLS.CertificateAuthorities); err != nil {
+		return types.Config{}, err
+	}
+	for i, f := range cfg.Storage.Files {
+		if err := b.inlineContents(&cfg.Storage.Files[i].Contents); err != nil {
+			return types.Config{}, fmt.Errorf("file %q: %v", f.Path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// flatten replaces or appends the configs referenced by cfg the way Ignition
+// does when it renders a config, updating the fetcher with the timeouts and
+// CAs of each config before fetching the configs it references.
+func (b *bundler) flatten(cfg types.Config) (types.Config, error) {
+	if ref := cfg.Ignition.Config.Replace; ref != nil {
+		newCfg, err := b.fetchConfig(*ref)
+		if err != nil {
+			return types.Config{}, err
+		}
+		if err := b.fetcher.UpdateHttpTimeoutsAndCAs(newCfg.Ignition.Timeouts, newCfg.Ignition.Security.TLS.CertificateAuthorities, newCfg.Ignition.Proxy); err != nil {
+			return types.Config{}, err
+		}
+		return b.flatten(newCfg)
+	}
+
+	flattened := cfg
+	for _, ref := range cfg.Ignition.Config.Append {
+		newCfg, err := b.fetchConfig(ref)
+		if err != nil {
+			return types.Config{}, err
+		}
+		combined := b.combine(flattened, newCfg)
+		if err := b.fetcher.UpdateHttpTimeoutsAndCAs(combined.Ignition.Timeouts, combined.Ignition.Security.TLS.CertificateAuthorities, combined.Ignition.Proxy); err != nil {
+			return types.Config{}, err
+		}
+		newCfg, err = b.flatten(newCfg)
+		if err != nil {
+			return types.Config{}, err
+		}
+		flattened = b.combine(flattened, newCfg)
+	}
+	return flattened, nil
+}
+
+func (b *bundler) combine(oldConfig, newConfig types.Config) types.Config {
+	if b.merge {
+		return config.Merge(oldConfig, newConfig)
+	}
+	return config.Append(oldConfig, newConfig)
+}
+
+// fetchConfig fetches and parses a referenced config, trying its mirrors in
+// order. An error is returned if the config is invalid.
+func (b *bundler) fetchConfig(ref types.ConfigReference) (types.Config, error) {
+	sources := []url.URL{}
+	for _, s := range append([]string{ref.Source}, mirrorStrings(ref.Mirrors)...) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return types.Config{}, err
+		}
+		sources = append(sources, *u)
+	}
+
+	raw, u, err := b.fetcher.FetchToBufferFromAny(sources, resource.FetchOptions{
+		Headers: resource.AddHeaders(resource.ConfigHeaders, ref.HTTPHeaders),
+	}, func(_ url.URL, raw []byte) error {
+		return util.AssertValid(ref.Verification, raw)
+	})
+	if err != nil {
+		return types.Config{}, fmt.Errorf("config %s: %v", ref.Source, err)
+	}
+
+	cfg, rpt, err := config.Parse(raw)
+	if b.printReport != nil {
+		b.printReport(rpt, u.String())
+	}
+	if err != nil {
+		return types.Config{}, fmt.Errorf("config %s: %v", u.String(), err)
+	}
+	return cfg, nil
+}
+
+// inlineContents fetches contents from a source which needs the network and
+// replaces the source with a data url, gzip-compressed if b.compress is set.
+// A detached signature is inlined too, since it covers the decompressed
+// contents. Other sources are left alone.
+func (b *bundler) inlineContents(c *types.FileContents) error {
+	u, err := url.Parse(c.Source)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "tftp", "s3":
+	default:
+		return nil
+	}
+
+	data, err := b.fetcher.FetchToBuffer(*u, resource.FetchOptions{
+		Headers:     resource.AddHeaders(nil, c.HTTPHeaders),
+		Compression: c.Compression,
+	})
+	if err != nil {
+		return err
+	}
+	if err := util.AssertValid(c.Verification, data); err != nil {
+		return err
+	}
+	if err := b.fetcher.InlineSignature(&c.Verification, c.HTTPHeaders); err != nil {
+		return err
+	}
+
+	c.HTTPHeaders = nil
+	c.Compression = ""
+	if b.compress {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+		c.Compression = "gzip"
+	}
+	c.Source = dataurl.EncodeBytes(data)
+	return nil
+}
+
+func mirrorStrings(mirrors []types.ConfigReferenceMirror) []string {
+	ss := make([]string, 0, len(mirrors))
+	for _, m := range mirrors {
+		ss = append(ss, string(m))
+	}
+	return ss
+}
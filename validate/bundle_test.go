@@ -0,0 +1,135 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+	"github.com/coreos/ignition/internal/util"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+func sha512Hash(data string) *string {
+	sum := sha512.Sum512([]byte(data))
+	h := "sha512-" + hex.EncodeToString(sum[:])
+	return &h
+}
+
+func newTestBundler(compress bool) bundler {
+	logger := log.NewWithOps(log.Stderr{})
+	return bundler{fetcher: resource.Fetcher{Logger: &logger}, compress: compress}
+}
+
+func TestBundle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hostname":
+			w.Write([]byte("example\n"))
+		case "/append.ign":
+			w.Write([]byte(`{
+				"ignition": {"version": "2.2.0-experimental"},
+				"storage": {"files": [{"filesystem": "root", "path": "/etc/motd", "mode": 420, "contents": {"source": "data:,hello"}}]}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	mode := 420
+	cfg := types.Config{
+		Ignition: types.Ignition{
+			Version: types.MaxVersion.String(),
+			Config: types.IgnitionConfig{
+				Append: []types.ConfigReference{{
+					Source:  server.URL + "/missing.ign",
+					Mirrors: []types.ConfigReferenceMirror{types.ConfigReferenceMirror(server.URL + "/append.ign")},
+				}},
+			},
+		},
+		Storage: types.Storage{Files: []types.File{{
+			Node: types.Node{Filesystem: "root", Path: "/etc/hostname"},
+			FileEmbedded1: types.FileEmbedded1{
+				Mode: &mode,
+				Contents: types.FileContents{
+					Source:       server.URL + "/hostname",
+					Verification: types.Verification{Hash: sha512Hash("example\n")},
+				},
+			},
+		}}},
+	}
+
+	b := newTestBundler(false)
+	out, err := b.bundle(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(out.Ignition.Config, types.IgnitionConfig{}) {
+		t.Errorf("references weren't removed: %+v", out.Ignition.Config)
+	}
+	sources := []string{}
+	for _, f := range out.Storage.Files {
+		sources = append(sources, f.Contents.Source)
+	}
+	expected := []string{dataurl.EncodeBytes([]byte("example\n")), "data:,hello"}
+	if !reflect.DeepEqual(expected, sources) {
+		t.Errorf("bad sources: want %v, got %v", expected, sources)
+	}
+
+	b = newTestBundler(true)
+	out, err = b.bundle(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	contents := out.Storage.Files[0].Contents
+	if contents.Compression != "gzip" {
+		t.Fatalf("contents weren't compressed: %+v", contents)
+	}
+	u, err := dataurl.DecodeString(contents.Source)
+	if err != nil {
+		t.Fatalf("bad data url: %v", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(u.Data))
+	if err != nil {
+		t.Fatalf("bad gzip stream: %v", err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("bad gzip stream: %v", err)
+	}
+	if err := util.AssertValid(contents.Verification, data); err != nil {
+		t.Errorf("decompressed contents don't match the hash: %v", err)
+	}
+
+	cfg.Storage.Files[0].Contents.Verification.Hash = sha512Hash("other\n")
+	b = newTestBundler(false)
+	if _, err := b.bundle(cfg); err == nil {
+		t.Errorf("expected a hash mismatch")
+	}
+}
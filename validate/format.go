@@ -0,0 +1,172 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coreos/ignition/config/validate/report"
+	"github.com/coreos/ignition/internal/version"
+)
+
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+
+	sarifSchema  = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/cos02/schemas/sarif-schema-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+var formats = []string{formatText, formatJSON, formatSARIF}
+
+// jsonEntry is a report entry along with the config it was found in.
+type jsonEntry struct {
+	report.Entry
+	File string `json:"file"`
+}
+
+// The subset of the SARIF format (https://sarifweb.azurewebsites.net) needed
+// to describe a report.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	InformationURI string `json:"informationUri"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+// formatReport renders the entries of r, found in the config at file, in the
+// given format. Text reports of no entries are empty.
+func formatReport(r report.Report, file, format string) (string, error) {
+	switch format {
+	case formatText:
+		return r.String(), nil
+	case formatJSON:
+		entries := []jsonEntry{}
+		for _, e := range r.Entries {
+			entries = append(entries, jsonEntry{Entry: e, File: file})
+		}
+		out, err := json.MarshalIndent(entries, "", "  ")
+		return string(out), err
+	case formatSARIF:
+		out, err := json.MarshalIndent(sarifReport(r, file), "", "  ")
+		return string(out), err
+	default:
+		return "", fmt.Errorf("unknown format %q, expected one of %v", format, formats)
+	}
+}
+
+func sarifReport(r report.Report, file string) sarifLog {
+	results := []sarifResult{}
+	for _, e := range r.Entries {
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: file},
+			},
+		}
+		if e.Line != 0 {
+			location.PhysicalLocation.Region = &sarifRegion{
+				StartLine:   e.Line,
+				StartColumn: e.Column,
+			}
+			if e.Highlight != "" {
+				location.PhysicalLocation.Region.Snippet = &sarifMessage{Text: e.Highlight}
+			}
+		}
+		if e.Path != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: e.Path}}
+		}
+		results = append(results, sarifResult{
+			RuleID:    e.Code,
+			Level:     sarifLevel(e),
+			Message:   sarifMessage{Text: e.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "ignition-validate",
+				Version:        version.Raw,
+				InformationURI: "https://github.com/coreos/ignition",
+			}},
+			Results: results,
+		}},
+	}
+}
+
+func sarifLevel(e report.Entry) string {
+	switch e.Kind {
+	case report.EntryError:
+		return "error"
+	case report.EntryWarning, report.EntryDeprecated:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestFormatReportJSON(t *testing.T) {
+	r := report.Report{Entries: []report.Entry{
+		{Kind: report.EntryError, Message: "bad", Code: "IGN-COMMON-001", Path: "storage.files.0.path", Line: 2, Column: 3, Highlight: "^"},
+		{Kind: report.EntryDeprecated, Message: "old"},
+	}}
+	expected := `[
+  {
+    "kind": "error",
+    "message": "bad",
+    "code": "IGN-COMMON-001",
+    "path": "storage.files.0.path",
+    "line": 2,
+    "column": 3,
+    "highlight": "^",
+    "file": "config.ign"
+  },
+  {
+    "kind": "deprecated",
+    "message": "old",
+    "file": "config.ign"
+  }
+]`
+
+	out, err := formatReport(r, "config.ign", formatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != expected {
+		t.Errorf("bad output: want %s, got %s", expected, out)
+	}
+}
+
+func TestSarifReport(t *testing.T) {
+	r := report.Report{Entries: []report.Entry{
+		{Kind: report.EntryWarning, Message: "bad", Code: "IGN-SYSTEMD-001", Path: "systemd.units.1.name", Line: 2, Column: 3, Highlight: "^"},
+		{Kind: report.EntryInfo, Message: "note"},
+	}}
+	expected := []sarifResult{
+		{
+			RuleID:  "IGN-SYSTEMD-001",
+			Level:   "warning",
+			Message: sarifMessage{Text: "bad"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "config.ign"},
+					Region: &sarifRegion{
+						StartLine:   2,
+						StartColumn: 3,
+						Snippet:     &sarifMessage{Text: "^"},
+					},
+				},
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "systemd.units.1.name"}},
+			}},
+		},
+		{
+			Level:   "note",
+			Message: sarifMessage{Text: "note"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "config.ign"},
+			}}},
+		},
+	}
+
+	log := sarifReport(r, "config.ign")
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("bad log: %+v", log)
+	}
+	if !reflect.DeepEqual(expected, log.Runs[0].Results) {
+		t.Errorf("bad results: want %+v, got %+v", expected, log.Runs[0].Results)
+	}
+
+	if _, err := formatReport(r, "config.ign", "yaml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -1,28 +1,63 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/lint"
+	"github.com/coreos/ignition/config/types"
+	v2_0 "github.com/coreos/ignition/config/v2_0/types"
+	v2_1 "github.com/coreos/ignition/config/v2_1/types"
+	"github.com/coreos/ignition/config/validate/report"
 	"github.com/coreos/ignition/internal/version"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	flagVersion bool
-	rootCmd     = &cobra.Command{
+	flagVersion      bool
+	flagLint         bool
+	flagLintStrict   bool
+	flagStrict       bool
+	flagFormat       string
+	flagSuppress     []string
+	flagTranslateTo  string
+	flagCompress     bool
+	flagMergeConfigs bool
+	rootCmd          = &cobra.Command{
 		Use:   "ignition-validate config.ign",
 		Short: "ignition-validate will validate Ignition configs",
+		Args:  cobra.ArbitraryArgs,
 		Run:   runIgnValidate,
 	}
+	translateCmd = &cobra.Command{
+		Use:   "translate [--to version] config.ign",
+		Short: "translate an Ignition config to another spec version, printing what changed",
+		Run:   runTranslate,
+	}
+	bundleCmd = &cobra.Command{
+		Use:   "bundle [--compress] config.ign",
+		Short: "inline the configs, files and CAs referenced by an Ignition config, printing a config which doesn't need the network",
+		Run:   runBundle,
+	}
 )
 
 func main() {
 	rootCmd.Flags().BoolVar(&flagVersion, "version", false, "print the version of ignition-validate")
+	rootCmd.Flags().BoolVar(&flagLint, "lint", false, "also warn about conflicts between parts of the config")
+	rootCmd.Flags().BoolVar(&flagLintStrict, "lint-strict", false, "lint the config like --lint, treating the findings as errors")
+	rootCmd.Flags().BoolVar(&flagStrict, "strict", false, "reject keys which aren't part of the spec")
+	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", formatText, fmt.Sprintf("format of the report %v", formats))
+	rootCmd.PersistentFlags().StringSliceVar(&flagSuppress, "suppress", nil, "codes of warnings to leave out of the report (errors can't be suppressed)")
+	translateCmd.Flags().StringVar(&flagTranslateTo, "to", types.MaxVersion.String(), fmt.Sprintf("spec version to translate to (%s, %s or %s)", types.MaxVersion, v2_1.MaxVersion, v2_0.MaxVersion))
+	bundleCmd.Flags().BoolVar(&flagCompress, "compress", false, "gzip the contents of inlined files")
+	bundleCmd.Flags().BoolVar(&flagMergeConfigs, "merge-configs", false, "merge entries with the same path or name when flattening appended configs instead of appending them")
+	rootCmd.AddCommand(translateCmd)
+	rootCmd.AddCommand(bundleCmd)
 	rootCmd.Execute()
 }
 
@@ -48,24 +83,123 @@ func runIgnValidate(cmd *cobra.Command, args []string) {
 		cmd.Usage()
 		os.Exit(1)
 	}
-	var blob []byte
-	var err error
-	if args[0] == "-" {
-		blob, err = ioutil.ReadAll(os.Stdin)
-	} else {
-		blob, err = ioutil.ReadFile(args[0])
+	parse := config.Parse
+	if flagStrict {
+		parse = config.ParseStrict
+	}
+	cfg, rpt, err := parse(readConfig(args[0]))
+	if err == nil && (flagLint || flagLintStrict) {
+		rpt.Merge(lint.Lint(cfg, flagLintStrict))
+	}
+	printReport(stdout, rpt, args[0])
+	if rpt.IsFatal() {
+		os.Exit(1)
 	}
 	if err != nil {
-		die("couldn't read config: %v", err)
+		die("couldn't parse config: %v", err)
 	}
-	_, rpt, err := config.Parse(blob)
-	if len(rpt.Entries) > 0 {
-		stdout(rpt.String())
+}
+
+func runTranslate(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		os.Exit(1)
 	}
+	out, rpt, err := translate(readConfig(args[0]), flagTranslateTo)
 	if rpt.IsFatal() {
+		printReport(stderr, rpt, args[0])
 		os.Exit(1)
 	}
 	if err != nil {
-		die("couldn't parse config: %v", err)
+		die("%v", err)
+	}
+	printReport(stderr, rpt, args[0])
+	stdout("%s", out)
+}
+
+// translate parses blob and translates it to spec version to, returning the
+// indented JSON of the translated config and a report of what was parsed,
+// translated and dropped along the way.
+func translate(blob []byte, to string) ([]byte, report.Report, error) {
+	cfg, rpt, err := config.Parse(blob)
+	if rpt.IsFatal() {
+		return nil, rpt, err
+	}
+	if err != nil {
+		return nil, rpt, fmt.Errorf("couldn't parse config: %v", err)
+	}
+
+	cfg.Ignition.Version = types.MaxVersion.String()
+
+	var translated interface{}
+	var dropped report.Report
+	switch to {
+	case types.MaxVersion.String():
+		translated = cfg
+	case v2_1.MaxVersion.String():
+		translated, dropped = config.TranslateToV2_1(cfg)
+	case v2_0.MaxVersion.String():
+		translated, dropped = config.TranslateToV2_0(cfg)
+	default:
+		return nil, rpt, fmt.Errorf("can't translate to spec version %q", to)
+	}
+	// Older configs are translated to the latest spec by Parse, so the
+	// version they came from has to be read separately.
+	if from, err := config.Version(blob); err == nil && from.String() != to {
+		rpt.Add(report.Entry{
+			Message: fmt.Sprintf("translated from spec %s to spec %s", from, to),
+			Kind:    report.EntryInfo,
+		})
+	}
+	rpt.Merge(dropped)
+
+	out, err := json.MarshalIndent(translated, "", "  ")
+	if err != nil {
+		return nil, rpt, fmt.Errorf("couldn't marshal config: %v", err)
+	}
+	return out, rpt, nil
+}
+
+// printReport prints r, found in the config at file, in the format selected
+// with --format and without the entries suppressed with --suppress. Empty text
+// reports aren't printed.
+func printReport(print func(string, ...interface{}), r report.Report, file string) {
+	checkCodes(flagSuppress)
+	r.Suppress(flagSuppress)
+	if flagFormat == formatText && len(r.Entries) == 0 {
+		return
+	}
+	out, err := formatReport(r, file, flagFormat)
+	if err != nil {
+		die("%v", err)
+	}
+	print("%s", out)
+}
+
+// checkCodes exits if any of codes isn't in the catalogue of codes, so that
+// typos don't silently suppress nothing.
+func checkCodes(codes []string) {
+	known := map[string]struct{}{}
+	for _, e := range report.Codes() {
+		known[e.Code] = struct{}{}
+	}
+	for _, c := range codes {
+		if _, ok := known[c]; !ok {
+			die("unknown code %q", c)
+		}
+	}
+}
+
+func readConfig(path string) []byte {
+	var blob []byte
+	var err error
+	if path == "-" {
+		blob, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		blob, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		die("couldn't read config: %v", err)
 	}
+	return blob
 }
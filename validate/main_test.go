@@ -0,0 +1,95 @@
+// Copyright 2018 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestTranslateUpgrade(t *testing.T) {
+	tests := []struct {
+		in     string
+		out    string
+		report report.Report
+	}{
+		{
+			in: `{"ignitionVersion": 1, "systemd": {"units": [{"name": "a.service", "enable": true}]}}`,
+			out: `{
+  "ignition": {"config": {}, "proxy": {}, "security": {"tls": {}}, "timeouts": {}, "version": "2.2.0-experimental"},
+  "networkd": {},
+  "passwd": {},
+  "storage": {},
+  "systemd": {"units": [{"enable": true, "name": "a.service"}]}
+}`,
+			report: report.Report{Entries: []report.Entry{
+				{Kind: report.EntryDeprecated, Message: "config format deprecated", Code: "IGN-CONFIG-004"},
+				{Kind: report.EntryInfo, Message: "translated from spec 1.0.0 to spec 2.2.0-experimental"},
+			}},
+		},
+		{
+			in: `{"ignition": {"version": "2.0.0"}, "storage": {"files": [{"filesystem": "root", "path": "/a", "contents": {"source": "data:,a"}}]}}`,
+			out: `{
+  "ignition": {"config": {}, "proxy": {}, "security": {"tls": {}}, "timeouts": {}, "version": "2.2.0-experimental"},
+  "networkd": {},
+  "passwd": {},
+  "storage": {"files": [{
+    "filesystem": "root",
+    "path": "/a",
+    "user": {"id": 0},
+    "group": {"id": 0},
+    "contents": {"source": "data:,a", "verification": {}},
+    "mode": 0
+  }]},
+  "systemd": {}
+}`,
+			report: report.Report{Entries: []report.Entry{
+				{Kind: report.EntryInfo, Message: "translated from spec 2.0.0 to spec 2.2.0-experimental"},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		out, rpt, err := translate([]byte(test.in), types.MaxVersion.String())
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		var want, got interface{}
+		if err := json.Unmarshal([]byte(test.out), &want); err != nil {
+			t.Fatalf("#%d: bad expected output: %v", i, err)
+		}
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Errorf("#%d: couldn't unmarshal output: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("#%d: bad config: want %s, got %s", i, test.out, out)
+		}
+		if !reflect.DeepEqual(test.report, rpt) {
+			t.Errorf("#%d: bad report: want %+v, got %+v", i, test.report, rpt)
+		}
+	}
+}
+
+func TestTranslateUnknownVersion(t *testing.T) {
+	if _, _, err := translate([]byte(`{"ignition": {"version": "2.0.0"}}`), "3.0.0"); err == nil {
+		t.Error("expected an error translating to an unknown spec version")
+	}
+}